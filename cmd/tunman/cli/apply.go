@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/Phillezi/tunman/internal/connection"
+	"github.com/Phillezi/tunman/internal/parser"
+	"github.com/Phillezi/tunman/interrupt"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/Phillezi/tunman/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// tunnelsFile is the format of the file given to apply.
+type tunnelsFile struct {
+	// Name identifies the forwards owned by the file, defaults to the absolute path of the file
+	Name    string       `yaml:"name"`
+	Tunnels []tunnelSpec `yaml:"tunnels"`
+}
+
+type tunnelSpec struct {
+	Target   string `yaml:"target"`
+	User     string `yaml:"user"`
+	Port     string `yaml:"port"`
+	Password string `yaml:"password"`
+	// TunnelLabels are added to the tunnel
+	TunnelLabels map[string]string `yaml:"tunnel-labels"`
+	fwdSpec      `yaml:",inline"`
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Converge the forwards of the daemon to a tunnels file",
+	Long: `The apply command reads a YAML file describing tunnels and their forwards and makes the daemon match it,
+forwards that are missing are opened, forwards that have changed are replaced and forwards that are no longer in the file are closed.
+Only forwards that were opened by applying the same file are touched, forwards opened using open or by other files are left alone.
+The forwards are owned by the name given in the file, or by the absolute path of the file if it has no name.
+
+Every tunnel takes a target using the same syntax as open, and the same forwards and options as the flags of open:
+
+name: team-prod
+tunnels:
+  - target: prod-db
+    lazy: true
+    labels:
+      env: prod
+    publish:
+      - localhost:5432:localhost:5432
+    allow:
+      - 127.0.0.1
+  - target: deploy@bastion.example.com:2222
+    dynamic:
+      - localhost:1080
+    socks-resolve: remote
+
+Use --dry-run to print the changes without applying them.`,
+	Example: `tunman apply -f tunnels.yaml
+# The command above will open, replace and close forwards so that the daemon matches tunnels.yaml
+
+tunman apply -f tunnels.yaml --dry-run
+# The command above will only print what would be changed`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := viper.GetString("apply.file")
+		if path == "" {
+			return fmt.Errorf("no tunnels file provided, use -f")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var file tunnelsFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		owner := file.Name
+		if owner == "" {
+			if owner, err = filepath.Abs(path); err != nil {
+				return err
+			}
+		}
+
+		tunnels := make([]*ctrlpb.Tunnel, 0, len(file.Tunnels))
+		for i, t := range file.Tunnels {
+			if t.Target == "" {
+				return fmt.Errorf("tunnel %d has no target", i)
+			}
+			addrPairs, err := t.addrPairs()
+			if err != nil {
+				return fmt.Errorf("tunnel %s: %w", t.Target, err)
+			}
+			u, h, p := parser.ParseTargetLoose(t.Target)
+			tunnels = append(tunnels, &ctrlpb.Tunnel{
+				User:        utils.Or(t.User, u),
+				Host:        h,
+				Port:        utils.ParsePort(utils.Or(t.Port, p)),
+				Pw:          t.Password,
+				AddressPair: addrPairs,
+				Labels:      t.TunnelLabels,
+			})
+		}
+
+		if conn := connection.C(); conn != nil {
+			dryRun := viper.GetBool("apply.dry-run")
+			resp, err := conn.Apply(interrupt.GetInstance().Context(), &ctrlpb.ApplyRequest{
+				Owner:   owner,
+				Tunnels: tunnels,
+				DryRun:  dryRun,
+			})
+			if err != nil {
+				fmt.Println(err.Error())
+				// not a input error, it is a connection error
+				return nil
+			}
+			for _, err := range resp.Errors {
+				zap.L().Error("error occurred when applying tunnels file", zap.Error(fmt.Errorf("%s", err)))
+			}
+			printPlan(resp.Changes, dryRun)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().StringP("file", "f", "", "Tunnels file to apply")
+	viper.BindPFlag("apply.file", applyCmd.Flags().Lookup("file"))
+
+	applyCmd.Flags().Bool("dry-run", false, "Print the changes without applying them")
+	viper.BindPFlag("apply.dry-run", applyCmd.Flags().Lookup("dry-run"))
+}
+
+func printPlan(changes []*ctrlpb.ApplyChange, dryRun bool) {
+	var changed int
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, c := range changes {
+		if c.Action == ctrlpb.ApplyAction_APPLY_UNCHANGED {
+			continue
+		}
+		changed++
+		fmt.Fprintf(w, "%s %s\t%s\t[%s]\t%s\t[%s]%s[%s]\n",
+			planSymbol(c.Action), planVerb(c.Action), c.Id, c.Host,
+			kindName(c.Addrs.GetKind()), c.Addrs.GetLocalAddr(), kindArrow(c.Addrs.GetKind()), fwdTarget(c.Addrs),
+		)
+	}
+	w.Flush()
+
+	if changed == 0 {
+		fmt.Println("no changes, forwards are up to date")
+		return
+	}
+	if dryRun {
+		fmt.Printf("%d changes planned, %d forwards unchanged\n", changed, len(changes)-changed)
+	} else {
+		fmt.Printf("%d changes applied, %d forwards unchanged\n", changed, len(changes)-changed)
+	}
+}
+
+func planSymbol(action ctrlpb.ApplyAction) string {
+	switch action {
+	case ctrlpb.ApplyAction_APPLY_OPEN:
+		return "+"
+	case ctrlpb.ApplyAction_APPLY_CLOSE:
+		return "-"
+	case ctrlpb.ApplyAction_APPLY_REPLACE:
+		return "~"
+	default:
+		return "="
+	}
+}
+
+func planVerb(action ctrlpb.ApplyAction) string {
+	switch action {
+	case ctrlpb.ApplyAction_APPLY_OPEN:
+		return "open"
+	case ctrlpb.ApplyAction_APPLY_CLOSE:
+		return "close"
+	case ctrlpb.ApplyAction_APPLY_REPLACE:
+		return "replace"
+	default:
+		return "keep"
+	}
+}
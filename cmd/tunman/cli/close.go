@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/Phillezi/tunman/internal/connection"
 	"github.com/Phillezi/tunman/interrupt"
@@ -16,24 +17,45 @@ var closeCmd = &cobra.Command{
 	Short: "Close a tunnel or multiple tunnels by ID or all",
 	Long: `The close command is used to terminate active tunnels previously opened by the daemon.
 You can close a specific tunnel or multiple tunnels by providing their IDs as arguments. These IDs are printed to stdout when a tunnel is opened.
+Instead of the full ID you can also use the name of a forward or any prefix of its ID, or of the part after the ".", that only matches a single forward.
+
+Forwards can also be closed by their labels, and the labels of their tunnels, using -l with the same selectors as ps.
 
 If you want to close **all** tunnels at once, you can either use the --all flag or pass "all" as the only argument.
 
-Note: Closing all tunnels using the "all" keyword or the --all flag will terminate every active tunnel managed by the daemon.`,
+Note: Closing all tunnels using the "all" keyword or the --all flag will terminate every active tunnel managed by the daemon.
+When the daemon is shared by several users only your own tunnels are closed,
+admins of the daemon can close the tunnels of other users, or all of them, with --all-users.`,
 	Example: `tunman close MTdlOTk3NTE4YzVhZTRjYw.YmJlZTA1MzNiOTMwMzEwNQ
 # The command above will close the tunnel with the given ID
 
 tunman close MTdlOTk3NTE4YzVhZTRjYw.YmJlZTA1MzNiOTMwMzEwNQ ODE4ZmY5ODlmYWE5ZTIwOA.YzdjMWMyZDc2ODhiOWJkZA
 # The command above will close multiple tunnels by their IDs
 
+tunman close prod-db 3d74
+# The command above will close the forward named prod-db and the forward whose ID starts with 3d74
+
+tunman close -l env=staging
+# The command above will close all forwards labeled env=staging
+
 tunman close all
-# This will close all tunnels`,
-	Args: cobra.MinimumNArgs(1),
+# This will close all tunnels
+
+tunman close --all --all-users
+# This will close the tunnels of every user of the daemon`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !viper.GetBool("all") && len(viper.GetStringSlice("close.selector")) == 0 {
+			return fmt.Errorf("requires at least 1 id, --selector or --all")
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if conn := connection.C(); conn != nil {
-			if !viper.GetBool("all") && args[0] != "all" {
+			if !viper.GetBool("all") && (len(args) == 0 || args[0] != "all") {
 				resp, err := conn.CloseFwd(interrupt.GetInstance().Context(), &ctrlpb.CloseRequest{
-					Ids: args,
+					Ids:      args,
+					Selector: strings.Join(viper.GetStringSlice("close.selector"), ","),
+					AllUsers: viper.GetBool("close.all-users"),
 				})
 				if err != nil {
 					zap.L().Error("failed to execute close command", zap.Error(err))
@@ -43,13 +65,12 @@ tunman close all
 					for _, err := range resp.Errors {
 						zap.L().Error("error occurred when closing tunnel", zap.Error(fmt.Errorf("%s", err)))
 					}
-					return
 				}
 				for _, id := range resp.ClosedIds {
 					fmt.Println(id)
 				}
 			} else {
-				resp, err := conn.CloseAllFwds(interrupt.GetInstance().Context(), &ctrlpb.CloseAllRequest{})
+				resp, err := conn.CloseAllFwds(interrupt.GetInstance().Context(), &ctrlpb.CloseAllRequest{AllUsers: viper.GetBool("close.all-users")})
 				if err != nil {
 					zap.L().Error("failed to execute close all command", zap.Error(err))
 					return
@@ -70,4 +91,10 @@ func init() {
 
 	closeCmd.Flags().BoolP("all", "a", false, "Close all tunnels")
 	viper.BindPFlag("all", closeCmd.Flags().Lookup("all"))
+
+	closeCmd.Flags().StringSliceP("selector", "l", nil, "Close the forwards with matching labels, for example env=staging")
+	viper.BindPFlag("close.selector", closeCmd.Flags().Lookup("selector"))
+
+	closeCmd.Flags().Bool("all-users", false, "Allow closing the forwards of other users, only allowed for admins of the daemon")
+	viper.BindPFlag("close.all-users", closeCmd.Flags().Lookup("all-users"))
 }
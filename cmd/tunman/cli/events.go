@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Phillezi/tunman/internal/connection"
+	"github.com/Phillezi/tunman/interrupt"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/Phillezi/tunman/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events [ids...]",
+	Short: "Print events from the daemon as they happen",
+	Long: `The events command prints the events of the tunnels and forwards of the daemon until it is interrupted.
+The event types are tunnel_dialed, auth_failed, fwd_listening, conn_accepted, conn_closed, tunnel_lost, tunnel_reconnected and tunnel_closed.
+Use --filter to only print some of the types and pass forward IDs, names or ID prefixes to only print the events of those forwards and their tunnels.
+With --json every event is printed as a JSON object on its own line.`,
+	Example: `tunman events
+# The command above will print all events
+
+tunman events --filter tunnel_lost,tunnel_reconnected prod-db
+# The command above will print when the tunnel of the forward named prod-db is lost and reconnected
+
+tunman events --json | jq -r 'select(.type == "EVENT_AUTH_FAILED") | .host'
+# The command above will print the hosts that fail to authenticate`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		types, err := parseEventTypes(viper.GetStringSlice("events.filter"))
+		if err != nil {
+			return err
+		}
+		if conn := connection.C(); conn != nil {
+			ctx := interrupt.GetInstance().Context()
+			stream, err := conn.Watch(ctx, &ctrlpb.WatchRequest{Types: types, Ids: args})
+			if err != nil {
+				zap.L().Error("failed to do events command", zap.Error(err))
+				return nil
+			}
+			asJSON := viper.GetBool("events.json")
+			for {
+				ev, err := stream.Recv()
+				if err != nil {
+					if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+						zap.L().Error("error occurred when watching events", zap.Error(err))
+					}
+					return nil
+				}
+				if asJSON {
+					data, err := protojson.Marshal(ev)
+					if err != nil {
+						zap.L().Error("failed to marshal event", zap.Error(err))
+						continue
+					}
+					fmt.Println(string(data))
+					continue
+				}
+				printEvent(ev)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+
+	eventsCmd.Flags().StringSlice("filter", nil, "Only print events of these types, for example tunnel_lost,auth_failed")
+	viper.BindPFlag("events.filter", eventsCmd.Flags().Lookup("filter"))
+
+	eventsCmd.Flags().Bool("json", false, "Print the events as JSON lines")
+	viper.BindPFlag("events.json", eventsCmd.Flags().Lookup("json"))
+}
+
+// parseEventTypes parses event type names like tunnel_lost or tunnel-lost.
+func parseEventTypes(names []string) ([]ctrlpb.EventType, error) {
+	var types []ctrlpb.EventType
+	for _, name := range names {
+		key := "EVENT_" + strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(name), "-", "_"))
+		v, ok := ctrlpb.EventType_value[key]
+		if !ok || v == int32(ctrlpb.EventType_EVENT_UNKNOWN) {
+			return nil, fmt.Errorf("unknown event type %q", name)
+		}
+		types = append(types, ctrlpb.EventType(v))
+	}
+	return types, nil
+}
+
+func eventName(typ ctrlpb.EventType) string {
+	return strings.ToLower(strings.TrimPrefix(typ.String(), "EVENT_"))
+}
+
+func printEvent(ev *ctrlpb.Event) {
+	line := fmt.Sprintf("%s  %-18s  %-33s  [%s]",
+		time.Unix(0, ev.At).Format("2006-01-02 15:04:05.000"), eventName(ev.Type), utils.Or(ev.FwdId, ev.TunnelId), ev.Host)
+	if ev.Peer != "" {
+		line += " peer=" + ev.Peer
+	}
+	if ev.Error != "" {
+		line += " error=" + ev.Error
+	}
+	fmt.Println(line)
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Phillezi/tunman/internal/defaults"
+	"github.com/Phillezi/tunman/internal/parser"
+	"github.com/Phillezi/tunman/pkg/acl"
+	"github.com/Phillezi/tunman/pkg/labels"
+	"github.com/Phillezi/tunman/pkg/tunnel"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+)
+
+// fwdSpec describes the forwards to open on a single tunnel,
+// it is filled from the flags of open or from a tunnels file.
+type fwdSpec struct {
+	Publish       []string `yaml:"publish"`
+	Reverse       []string `yaml:"reverse"`
+	Dynamic       []string `yaml:"dynamic"`
+	HTTPProxy     []string `yaml:"http-proxy"`
+	SocketMode    string   `yaml:"socket-mode"`
+	SocksUser     string   `yaml:"socks-user"`
+	SocksPassword string   `yaml:"socks-password"`
+	SocksResolve  string   `yaml:"socks-resolve"`
+	Lazy          bool     `yaml:"lazy"`
+	Allow         []string `yaml:"allow"`
+	Deny          []string `yaml:"deny"`
+	// Labels are added to every forward
+	Labels map[string]string `yaml:"labels"`
+	// ExpiresAt is the unix time the forwards expire at, 0 if they do not
+	ExpiresAt int64 `yaml:"-"`
+}
+
+// addrPairs parses the spec into address pairs keyed by their forward hash.
+func (s *fwdSpec) addrPairs() (map[string]*ctrlpb.AddrPair, error) {
+	localRemoteMap, err := parser.ParsePublishes(s.Publish)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse, err: %s", err.Error())
+	}
+	remoteLocalMap, err := parser.ParsePublishes(s.Reverse)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reverse, err: %s", err.Error())
+	}
+	dynamicAddrs, err := parser.ParseListenAddrs(s.Dynamic, defaults.DefaultProxyHost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dynamic, err: %s", err.Error())
+	}
+	httpProxyAddrs, err := parser.ParseListenAddrs(s.HTTPProxy, defaults.DefaultProxyHost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse http-proxy, err: %s", err.Error())
+	}
+	if len(localRemoteMap) == 0 && len(remoteLocalMap) == 0 && len(dynamicAddrs) == 0 && len(httpProxyAddrs) == 0 {
+		return nil, fmt.Errorf("no forwards provided")
+	}
+
+	if s.Lazy && len(remoteLocalMap) > 0 {
+		return nil, fmt.Errorf("remote forwards can not be lazy")
+	}
+
+	if _, err := acl.New(s.Allow, s.Deny); err != nil {
+		return nil, err
+	}
+	if err := labels.Validate(s.Labels); err != nil {
+		return nil, err
+	}
+
+	var socketMode uint64
+	if s.SocketMode != "" {
+		socketMode, err = strconv.ParseUint(s.SocketMode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid socket-mode %q, expected octal permissions like 0600", s.SocketMode)
+		}
+	}
+
+	var resolveLocal bool
+	switch s.SocksResolve {
+	case "", "remote":
+	case "local":
+		resolveLocal = true
+	default:
+		return nil, fmt.Errorf("invalid socks-resolve value %q, expected remote or local", s.SocksResolve)
+	}
+
+	addrPairs := make(map[string]*ctrlpb.AddrPair, len(localRemoteMap)+len(remoteLocalMap)+len(dynamicAddrs)+len(httpProxyAddrs))
+	for l, r := range localRemoteMap {
+		addrPairs[tunnel.HashFwd(ctrlpb.FwdKind_FWD_LOCAL, l, r)] = &ctrlpb.AddrPair{
+			LocalAddr:  l,
+			RemoteAddr: r,
+			Kind:       ctrlpb.FwdKind_FWD_LOCAL,
+			SocketMode: uint32(socketMode),
+			ExpiresAt:  s.ExpiresAt,
+			Allow:      s.Allow,
+			Deny:       s.Deny,
+			Labels:     s.Labels,
+			Lazy:       s.Lazy,
+		}
+	}
+	for r, l := range remoteLocalMap {
+		addrPairs[tunnel.HashFwd(ctrlpb.FwdKind_FWD_REMOTE, l, r)] = &ctrlpb.AddrPair{
+			LocalAddr:  l,
+			RemoteAddr: r,
+			Kind:       ctrlpb.FwdKind_FWD_REMOTE,
+			SocketMode: uint32(socketMode),
+			ExpiresAt:  s.ExpiresAt,
+			Allow:      s.Allow,
+			Deny:       s.Deny,
+			Labels:     s.Labels,
+		}
+	}
+	for _, l := range dynamicAddrs {
+		addrPairs[tunnel.HashFwd(ctrlpb.FwdKind_FWD_DYNAMIC, l, "")] = &ctrlpb.AddrPair{
+			LocalAddr:  l,
+			Kind:       ctrlpb.FwdKind_FWD_DYNAMIC,
+			SocketMode: uint32(socketMode),
+			ExpiresAt:  s.ExpiresAt,
+			Allow:      s.Allow,
+			Deny:       s.Deny,
+			Labels:     s.Labels,
+			Lazy:       s.Lazy,
+			Socks: &ctrlpb.SocksOpts{
+				User:         s.SocksUser,
+				Password:     s.SocksPassword,
+				ResolveLocal: resolveLocal,
+			},
+		}
+	}
+	for _, l := range httpProxyAddrs {
+		addrPairs[tunnel.HashFwd(ctrlpb.FwdKind_FWD_HTTP, l, "")] = &ctrlpb.AddrPair{
+			LocalAddr:  l,
+			Kind:       ctrlpb.FwdKind_FWD_HTTP,
+			SocketMode: uint32(socketMode),
+			ExpiresAt:  s.ExpiresAt,
+			Allow:      s.Allow,
+			Deny:       s.Deny,
+			Labels:     s.Labels,
+			Lazy:       s.Lazy,
+		}
+	}
+	return addrPairs, nil
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/Phillezi/tunman/internal/connection"
+	"github.com/Phillezi/tunman/interrupt"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect [ids...]",
+	Short: "Show the details of forwards",
+	Long: `The inspect command prints the details of one or more forwards as JSON, including their options, tunnel and statistics.
+Forwards can be referred to by ID, name or unambiguous ID prefix.`,
+	Example: `tunman inspect prod-db
+# The command above will show the details of the forward named prod-db
+
+tunman inspect 3d74feff23ed7204.fe19
+# The command above will show the details of the forward whose ID starts with 3d74feff23ed7204.fe19`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if conn := connection.C(); conn != nil {
+			resp, err := conn.Inspect(interrupt.GetInstance().Context(), &ctrlpb.InspectRequest{Ids: args})
+			if err != nil {
+				zap.L().Error("failed to do inspect command", zap.Error(err))
+				return
+			}
+			for _, err := range resp.Errors {
+				zap.L().Error("error occurred when doing inspect command", zap.Error(fmt.Errorf("%s", err)))
+			}
+			opts := protojson.MarshalOptions{Multiline: true, Indent: "  ", EmitDefaultValues: true}
+			for _, fwd := range resp.Fwds {
+				data, err := opts.Marshal(fwd)
+				if err != nil {
+					zap.L().Error("failed to marshal fwd", zap.Error(err))
+					continue
+				}
+				fmt.Println(string(data))
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Phillezi/tunman/internal/connection"
+	"github.com/Phillezi/tunman/internal/parser"
+	"github.com/Phillezi/tunman/interrupt"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <id>",
+	Short: "Show the daemon logs of a forward",
+	Long: `The logs command shows what the daemon has logged about a forward and its tunnel, such as failed dials, rejected connections and reconnects.
+The forward can be referred to by ID, name or unambiguous ID prefix.
+The daemon only keeps the latest entries of every forward in memory, entries are not kept across restarts.
+Use -f to keep printing new entries as they are logged and --since to only show recent entries.`,
+	Example: `tunman logs prod-db
+# The command above will show the logs of the forward named prod-db
+
+tunman logs prod-db -f --since 10m
+# The command above will show the logs of the last 10 minutes and then follow new entries`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := parser.ParseSince(viper.GetString("logs.since"), time.Now())
+		if err != nil {
+			return err
+		}
+		if conn := connection.C(); conn != nil {
+			req := &ctrlpb.LogsRequest{Id: args[0], Follow: viper.GetBool("logs.follow")}
+			if !since.IsZero() {
+				req.Since = since.UnixNano()
+			}
+			ctx := interrupt.GetInstance().Context()
+			stream, err := conn.Logs(ctx, req)
+			if err != nil {
+				zap.L().Error("failed to do logs command", zap.Error(err))
+				return nil
+			}
+			for {
+				entry, err := stream.Recv()
+				if err != nil {
+					if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+						zap.L().Error("error occurred when doing logs command", zap.Error(err))
+					}
+					return nil
+				}
+				printLogEntry(entry)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing new entries as they are logged")
+	viper.BindPFlag("logs.follow", logsCmd.Flags().Lookup("follow"))
+
+	logsCmd.Flags().String("since", "", "Only show entries since a duration ago (10m), a clock time (09:30) or a RFC3339 time")
+	viper.BindPFlag("logs.since", logsCmd.Flags().Lookup("since"))
+}
+
+func printLogEntry(entry *ctrlpb.LogEntry) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %-5s  %s", time.Unix(0, entry.At).Format("2006-01-02 15:04:05.000"), strings.ToUpper(entry.Level), entry.Message)
+	if entry.FwdId == "" {
+		b.WriteString("  tunnel=" + entry.TunnelId)
+	}
+	for _, k := range slices.Sorted(maps.Keys(entry.Fields)) {
+		fmt.Fprintf(&b, "  %s=%s", k, entry.Fields[k])
+	}
+	fmt.Println(b.String())
+}
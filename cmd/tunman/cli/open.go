@@ -3,10 +3,12 @@ package cli
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Phillezi/tunman/internal/connection"
 	"github.com/Phillezi/tunman/internal/parser"
 	"github.com/Phillezi/tunman/interrupt"
+	"github.com/Phillezi/tunman/pkg/labels"
 	sshutil "github.com/Phillezi/tunman/pkg/ssh"
 	ctrlpb "github.com/Phillezi/tunman/proto"
 	"github.com/Phillezi/tunman/utils"
@@ -24,13 +26,79 @@ things specified with flags take priority. You can also refer to custom hosts sp
 this config will be read and parsed to open the ssh connection, it even works with proxy-jumps.
 
 Specifying ports to "publish" takes inspiration from how it is done within the docker cli, using -p or --publish per pair you want to publish and ":" as a delimiter.
-Bind addresses are optionally specified, if omitted they default to 0.0.0.0.`,
+Bind addresses are optionally specified, if omitted they default to 0.0.0.0.
+Either side of a publish can also be an absolute unix socket path, for example -p /tmp/docker.sock:/var/run/docker.sock,
+socket paths on the ssh host are forwarded using the OpenSSH streamlocal extensions.
+Local sockets are created with the permissions given by --socket-mode (default 0600) and are removed when the forward is closed.
+When the daemon runs as another user, local sockets and ports are checked against your permissions, you can only create sockets
+in directories you may write to, connect reverse forwards to sockets you may connect to and use privileged ports as root.
+
+Remote (reverse) forwards, the equivalent of ssh -R, are specified using -R or --reverse with the same syntax,
+but the first address pair is the address the ssh host listens on and the second is the local address that connections are forwarded to.
+
+Dynamic forwards, the equivalent of ssh -D, are specified using -D or --dynamic with the syntax <local-addr>:<local-port>,
+the daemon will then serve SOCKS5 on that address and connect to the requested targets through the tunnel.
+Like ssh -D, dynamic forwards without a bind address only listen on 127.0.0.1, use *:<local-port> to listen on all interfaces.
+Optional username/password auth can be enabled with --socks-user and --socks-password,
+and --socks-resolve decides if hostnames are resolved on the ssh host (remote) or by the daemon (local).
+
+HTTP proxy forwards are specified using --http-proxy with the same syntax as dynamic forwards,
+the daemon will then serve a HTTP/1.1 proxy supporting CONNECT and absolute-URI requests on that address.
+The proxy does not authenticate its clients, so without a bind address it only listens on 127.0.0.1 too.
+
+Forwards opened with --lazy listen right away but the ssh connection is not opened until the first connection is accepted,
+the connection is closed again once the forwards have been unused for the idle timeout of the daemon (--idle-timeout of tunmand).
+Remote forwards can not be lazy since they need the ssh connection to listen.
+
+Forwards can be given a deadline using --ttl or --until, the daemon closes and forgets them once it has passed,
+even if it was not running at the time. --until takes a clock time like 18:00, meaning the next time it is 18:00, or a RFC3339 time.
+
+Which clients may connect to the forwards can be limited using --allow and --deny with IPs or CIDRs,
+denied addresses take priority and if --allow is given all other addresses are rejected.
+Note that the daemon may be configured to refuse listening on non-loopback addresses (--loopback-only of tunmand).
+
+When run in a terminal, passwords and keyboard-interactive challenges of the ssh server, like one-time passwords
+of a bastion, are prompted for and answered on the terminal. Answers are only shown while typed if the server allows it.
+Since the answers are not kept, a tunnel that was opened using them can not reconnect on its own and has to be opened again.
+The passphrases of encrypted private keys are asked for the same way, up to three times, unless the daemon gets them from its
+--passphrase-command first. SSH_ASKPASS of the daemon is only used when there is no terminal to ask. Decrypted keys are kept
+in the memory of the daemon for the user that opened the tunnel (see --key-cache-lifetime of tunmand), so reconnecting works.
+
+Forwards and tunnels can be labeled using -l and --tunnel-label with key=value pairs, the labels can then be used
+to select forwards in other commands, for example "tunman ps -l env=prod" or "tunman close -l env=staging".`,
 	Example: `tunman open testserver -p 8080:8080 -p 9090:7070 -p 5050:10.0.12.1:5050 -p localhost:4040:4040
 # The command above will look up testserver in the users (the user running the daemon) ~/.ssh/config and open a tunnel
 # it will then forward the published port address combinations that are specified
 
 tunman open root@localhost:2222 -p 8080:8090
-# The command above will open a tunnel and forward port 8090 inside the ssh host to 8080 of the host running the command.`,
+# The command above will open a tunnel and forward port 8090 inside the ssh host to 8080 of the host running the command.
+
+tunman open testserver -p /tmp/testserver-docker.sock:/var/run/docker.sock -p 5432:/var/run/postgresql/.s.PGSQL.5432
+# The command above will forward the docker socket of testserver to a local socket and the postgres socket to local port 5432.
+
+tunman open testserver -R 8080:localhost:3000
+# The command above will listen on port 8080 on testserver and forward connections to localhost:3000 of the host running the daemon.
+
+tunman open testserver -D localhost:1080
+# The command above will serve SOCKS5 on localhost:1080 and connect to the requested targets from testserver.
+
+tunman open testserver --http-proxy localhost:3128
+# The command above will serve a HTTP proxy on localhost:3128 and connect to the requested targets from testserver.
+
+tunman open testserver --lazy -p 5432:5432
+# The command above will listen on port 5432 but only connect to testserver once something connects to it.
+
+tunman open production --name prod-db -p 5432:5432
+# The command above will forward port 5432 of production, the forward can then be referred to as prod-db, for example "tunman close prod-db".
+
+tunman open staging -l env=staging -l project=billing -p 5432:5432
+# The command above will forward port 5432 of staging with the labels env=staging and project=billing.
+
+tunman open production --ttl 2h -p 5432:5432
+# The command above will forward port 5432 of production for two hours.
+
+tunman open production -p 5432:5432 --allow 127.0.0.1 --allow 10.0.12.0/24 --deny 10.0.12.7
+# The command above will only accept connections from localhost and the 10.0.12.0/24 network except 10.0.12.7.`,
 	Args: cobra.MinimumNArgs(1),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
 		if len(args) > 0 {
@@ -53,30 +121,68 @@ tunman open root@localhost:2222 -p 8080:8090
 		userVal := utils.Or(viper.GetString("uservalue"), u)
 		pw := viper.GetString("password")
 
-		localRemoteMap, err := parser.ParsePublishes(viper.GetStringSlice("publish"))
+		deadline, err := parser.ParseDeadline(viper.GetDuration("ttl"), viper.GetString("until"), time.Now())
+		if err != nil {
+			return err
+		}
+		var expiresAt int64
+		if !deadline.IsZero() {
+			expiresAt = deadline.Unix()
+		}
+
+		fwdLabels, err := labels.Parse(viper.GetStringSlice("label"))
 		if err != nil {
-			return fmt.Errorf("failed to parse, err: %s", err.Error())
+			return err
 		}
-		if len(localRemoteMap) == 0 {
-			return fmt.Errorf("no forwards provided")
+		tunnelLabels, err := labels.Parse(viper.GetStringSlice("tunnel-label"))
+		if err != nil {
+			return err
 		}
 
-		addrPairs := make(map[string]*ctrlpb.AddrPair, len(localRemoteMap))
-		for l, r := range localRemoteMap {
-			addrPairs[l] = &ctrlpb.AddrPair{
-				LocalAddr:  l,
-				RemoteAddr: r,
+		spec := fwdSpec{
+			Publish:       viper.GetStringSlice("publish"),
+			Reverse:       viper.GetStringSlice("reverse"),
+			Dynamic:       viper.GetStringSlice("dynamic"),
+			HTTPProxy:     viper.GetStringSlice("http-proxy"),
+			SocketMode:    viper.GetString("socket-mode"),
+			SocksUser:     viper.GetString("socks-user"),
+			SocksPassword: viper.GetString("socks-password"),
+			SocksResolve:  viper.GetString("socks-resolve"),
+			Lazy:          viper.GetBool("lazy"),
+			Allow:         viper.GetStringSlice("allow"),
+			Deny:          viper.GetStringSlice("deny"),
+			ExpiresAt:     expiresAt,
+			Labels:        fwdLabels,
+		}
+		addrPairs, err := spec.addrPairs()
+		if err != nil {
+			return err
+		}
+		if name := viper.GetString("name"); name != "" {
+			if len(addrPairs) != 1 {
+				return fmt.Errorf("--name can only be used when opening a single forward")
+			}
+			for _, a := range addrPairs {
+				a.Name = name
 			}
 		}
 
 		if conn := connection.C(); conn != nil {
-			resp, err := conn.OpenFwd(interrupt.GetInstance().Context(), &ctrlpb.OpenRequest{Tunnels: []*ctrlpb.Tunnel{{
+			req := &ctrlpb.OpenRequest{Tunnels: []*ctrlpb.Tunnel{{
 				User:        utils.Or(userVal),
 				Host:        host,
 				Port:        utils.ParsePort(utils.Or(port)),
 				Pw:          pw,
 				AddressPair: addrPairs,
-			}}})
+				Labels:      tunnelLabels,
+			}}}
+			ctx := interrupt.GetInstance().Context()
+			var resp *ctrlpb.OpenResponse
+			if canPrompt() {
+				resp, err = openInteractive(ctx, conn, req)
+			} else {
+				resp, err = conn.OpenFwd(ctx, req)
+			}
 			if err != nil {
 				fmt.Println(err.Error())
 				// not a input error, it is a connection error
@@ -108,5 +214,51 @@ func init() {
 	openCmd.Flags().StringSliceP("publish", "p", nil, "Publish forwards, syntax <local-addr>:<local-port>:<remote-addr>:<local-port>, if \"<local-addr>:\" or \"<remote-addr>:\" is omitted then 0.0.0.0 will be used")
 	viper.BindPFlag("publish", openCmd.Flags().Lookup("publish"))
 
+	openCmd.Flags().String("socket-mode", "", "Permissions of local unix sockets created by forwards, in octal (default 0600)")
+	viper.BindPFlag("socket-mode", openCmd.Flags().Lookup("socket-mode"))
+
+	openCmd.Flags().String("name", "", "Unique name of the forward, can be used instead of its id")
+	viper.BindPFlag("name", openCmd.Flags().Lookup("name"))
+
+	openCmd.Flags().StringSliceP("label", "l", nil, "Add a key=value label to the forwards")
+	viper.BindPFlag("label", openCmd.Flags().Lookup("label"))
+
+	openCmd.Flags().StringSlice("tunnel-label", nil, "Add a key=value label to the tunnel, the labels of a tunnel apply to all its forwards")
+	viper.BindPFlag("tunnel-label", openCmd.Flags().Lookup("tunnel-label"))
+
+	openCmd.Flags().Bool("lazy", false, "Do not connect until a forward accepts a connection, and disconnect again when idle")
+	viper.BindPFlag("lazy", openCmd.Flags().Lookup("lazy"))
+
+	openCmd.Flags().Duration("ttl", 0, "Close the forwards after this long, for example 2h")
+	viper.BindPFlag("ttl", openCmd.Flags().Lookup("ttl"))
+
+	openCmd.Flags().String("until", "", "Close the forwards at this time, a clock time like 18:00 or a RFC3339 time")
+	viper.BindPFlag("until", openCmd.Flags().Lookup("until"))
+	openCmd.MarkFlagsMutuallyExclusive("ttl", "until")
+
+	openCmd.Flags().StringSlice("allow", nil, "Only accept connections from these IPs or CIDRs")
+	viper.BindPFlag("allow", openCmd.Flags().Lookup("allow"))
+
+	openCmd.Flags().StringSlice("deny", nil, "Reject connections from these IPs or CIDRs")
+	viper.BindPFlag("deny", openCmd.Flags().Lookup("deny"))
+
+	openCmd.Flags().StringSliceP("reverse", "R", nil, "Publish remote (reverse) forwards, syntax <remote-addr>:<remote-port>:<local-addr>:<local-port>, if \"<remote-addr>:\" or \"<local-addr>:\" is omitted then 0.0.0.0 will be used")
+	viper.BindPFlag("reverse", openCmd.Flags().Lookup("reverse"))
+
+	openCmd.Flags().StringSliceP("dynamic", "D", nil, "Publish dynamic (SOCKS5) forwards, syntax <local-addr>:<local-port>, if \"<local-addr>:\" is omitted then 127.0.0.1 will be used")
+	viper.BindPFlag("dynamic", openCmd.Flags().Lookup("dynamic"))
+
+	openCmd.Flags().StringSlice("http-proxy", nil, "Publish HTTP proxy forwards, syntax <local-addr>:<local-port>, if \"<local-addr>:\" is omitted then 127.0.0.1 will be used")
+	viper.BindPFlag("http-proxy", openCmd.Flags().Lookup("http-proxy"))
+
+	openCmd.Flags().String("socks-user", "", "Require this username on dynamic forwards")
+	viper.BindPFlag("socks-user", openCmd.Flags().Lookup("socks-user"))
+
+	openCmd.Flags().String("socks-password", "", "Require this password on dynamic forwards")
+	viper.BindPFlag("socks-password", openCmd.Flags().Lookup("socks-password"))
+
+	openCmd.Flags().String("socks-resolve", "remote", "Where dynamic forwards resolve hostnames (remote or local)")
+	viper.BindPFlag("socks-resolve", openCmd.Flags().Lookup("socks-resolve"))
+
 	rootCmd.AddCommand(openCmd)
 }
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"golang.org/x/term"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// canPrompt reports if the prompts of ssh servers can be answered on the terminal.
+func canPrompt() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// openInteractive opens the forwards of req and answers the prompts of the ssh servers on the terminal,
+// daemons that can not relay prompts open them without.
+func openInteractive(ctx context.Context, conn ctrlpb.TunnelServiceClient, req *ctrlpb.OpenRequest) (*ctrlpb.OpenResponse, error) {
+	stream, err := conn.OpenFwdInteractive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(&ctrlpb.OpenInteractiveRequest{Msg: &ctrlpb.OpenInteractiveRequest_Open{Open: req}}); err != nil {
+		return nil, err
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+	for {
+		msg, err := stream.Recv()
+		if status.Code(err) == codes.Unimplemented {
+			return conn.OpenFwd(ctx, req)
+		} else if err != nil {
+			return nil, err
+		}
+		if resp := msg.GetResult(); resp != nil {
+			stream.CloseSend()
+			return resp, nil
+		}
+
+		prompt := msg.GetPrompt()
+		answer := &ctrlpb.PromptAnswer{Id: prompt.GetId()}
+		if answer.Answers, err = answerPrompt(ctx, stdin, prompt); err != nil {
+			fmt.Fprintln(os.Stderr)
+			answer = &ctrlpb.PromptAnswer{Id: prompt.GetId(), Cancel: true}
+		}
+		if err := stream.Send(&ctrlpb.OpenInteractiveRequest{Msg: &ctrlpb.OpenInteractiveRequest_Answer{Answer: answer}}); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// answerPrompt asks the questions of p on the terminal, answers are only shown while typed if the server allows it.
+func answerPrompt(ctx context.Context, stdin *bufio.Reader, p *ctrlpb.Prompt) ([]string, error) {
+	if p.Name != "" || p.Instruction != "" {
+		fmt.Fprintf(os.Stderr, "[%s@%s] %s\n", p.User, p.Host, strings.TrimSpace(strings.Join([]string{p.Name, p.Instruction}, "\n")))
+	}
+	answers := make([]string, 0, len(p.Questions))
+	for i, q := range p.Questions {
+		fmt.Fprint(os.Stderr, q)
+		var answer string
+		var err error
+		if i < len(p.Echos) && p.Echos[i] {
+			answer, err = stdin.ReadString('\n')
+		} else {
+			answer, err = readHidden(ctx)
+			fmt.Fprintln(os.Stderr)
+		}
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, strings.TrimRight(answer, "\r\n"))
+	}
+	return answers, nil
+}
+
+// readHidden reads a line from the terminal without echoing it,
+// the terminal is restored if ctx is canceled while reading.
+func readHidden(ctx context.Context) (string, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.GetState(fd)
+	if err != nil {
+		return "", err
+	}
+	type result struct {
+		line []byte
+		err  error
+	}
+	read := make(chan result, 1)
+	go func() {
+		line, err := term.ReadPassword(fd)
+		read <- result{line, err}
+	}()
+	select {
+	case r := <-read:
+		return string(r.line), r.err
+	case <-ctx.Done():
+		term.Restore(fd, state)
+		return "", ctx.Err()
+	}
+}
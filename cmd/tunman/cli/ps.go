@@ -2,19 +2,49 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/Phillezi/tunman/internal/connection"
 	"github.com/Phillezi/tunman/interrupt"
+	"github.com/Phillezi/tunman/pkg/labels"
 	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/Phillezi/tunman/utils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
 var psCmd = &cobra.Command{
-	Use: "ps",
+	Use:   "ps",
+	Short: "List forwards",
+	Long: `The ps command lists the forwards of the daemon and their status, forwards that are persisted but not running are listed too.
+A forward is pending until it is opened, dialing while its tunnel connects and listening once it accepts connections.
+It is degraded while connections to its target fail, reconnecting while its tunnel reconnects,
+paused while its lazy tunnel is idle and failed if it could not be opened.
+Forwards can be filtered by their labels, and the labels of their tunnels, using -l with selectors like
+key=value, key!=value, key (the label is set) and !key (the label is not set), all selectors have to match.
+
+Only your own forwards are listed when the daemon is shared by several users,
+admins of the daemon can list the forwards of every user with --all-users.`,
+	Example: `tunman ps -l env=prod
+# The command above will list the forwards labeled env=prod
+
+tunman ps -l project=billing,env!=prod
+# The command above will list the billing forwards that are not labeled env=prod
+
+tunman ps -A
+# The command above will list the forwards of all users of the daemon`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if conn := connection.C(); conn != nil {
-			resp, err := conn.Ps(interrupt.GetInstance().Context(), &ctrlpb.PsRequest{})
+			resp, err := conn.Ps(interrupt.GetInstance().Context(), &ctrlpb.PsRequest{
+				Selector: strings.Join(viper.GetStringSlice("ps.selector"), ","),
+				AllUsers: viper.GetBool("ps.all-users"),
+			})
 			if err != nil {
 				zap.L().Error("failed to do ps command", zap.Error(err))
 				return
@@ -26,17 +56,109 @@ var psCmd = &cobra.Command{
 				return
 			}
 			if len(resp.Fwds) <= 0 {
-				fmt.Println("no active forwards")
+				fmt.Println("no forwards")
 				return
 			}
-			fmt.Println("ID\tHOST\t\tFWD")
+			allUsers := viper.GetBool("ps.all-users")
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			if allUsers {
+				fmt.Fprint(w, "OWNER\t")
+			}
+			fmt.Fprintln(w, "ID\tNAME\tHOST\tDIR\tFWD\tSTATUS\tEXPIRES\tRTT\tRECONNECTS\tLAST ERROR\tLABELS")
 			for _, fwd := range resp.Fwds {
-				fmt.Printf("%s\t[%s:%d]\t[%s]:[%s]\n", fwd.Id, fwd.Parent.Host, fwd.Parent.Port, fwd.Addrs.LocalAddr, fwd.Addrs.RemoteAddr)
+				if allUsers {
+					fmt.Fprintf(w, "%s\t", ownerName(fwd.Parent.OwnerUid))
+				}
+				fmt.Fprintf(w, "%s\t%s\t[%s:%d]\t%s\t[%s]%s[%s]\t%s\t%s\t%s\t%d\t%s\t%s\n",
+					fwd.Id, utils.Or(fwd.Addrs.Name, "-"), fwd.Parent.Host, fwd.Parent.Port,
+					kindName(fwd.Addrs.Kind), fwd.Addrs.LocalAddr, kindArrow(fwd.Addrs.Kind), fwdTarget(fwd.Addrs),
+					fwdStatus(fwd), remaining(fwd.Addrs.ExpiresAt), rtt(fwd.Parent.RttNanos), fwd.Parent.ReconnectAttempts, utils.Or(fwd.LastError, fwd.Parent.LastError), utils.Or(labels.String(labels.Merge(fwd.Parent.Labels, fwd.Addrs.Labels)), "-"),
+				)
 			}
+			w.Flush()
 		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(psCmd)
+
+	psCmd.Flags().StringSliceP("selector", "l", nil, "Only list forwards with matching labels, for example env=prod")
+	viper.BindPFlag("ps.selector", psCmd.Flags().Lookup("selector"))
+
+	psCmd.Flags().BoolP("all-users", "A", false, "List the forwards of all users, only allowed for admins of the daemon")
+	viper.BindPFlag("ps.all-users", psCmd.Flags().Lookup("all-users"))
+}
+
+// ownerName is the name of the user with uid, or uid if it has no name.
+func ownerName(uid uint32) string {
+	id := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(id); err == nil {
+		return u.Username
+	}
+	return id
+}
+
+func kindName(kind ctrlpb.FwdKind) string {
+	return strings.ToLower(strings.TrimPrefix(kind.String(), "FWD_"))
+}
+
+// kindArrow points in the direction connections are forwarded.
+func kindArrow(kind ctrlpb.FwdKind) string {
+	if kind == ctrlpb.FwdKind_FWD_REMOTE {
+		return "<-"
+	}
+	return "->"
+}
+
+// fwdTarget is the remote side of a forward, dynamic and http proxy forwards have no fixed target.
+func fwdTarget(addrs *ctrlpb.AddrPair) string {
+	switch addrs.Kind {
+	case ctrlpb.FwdKind_FWD_DYNAMIC:
+		return "socks5"
+	case ctrlpb.FwdKind_FWD_HTTP:
+		return "http-proxy"
+	default:
+		return addrs.RemoteAddr
+	}
+}
+
+func fwdStatus(fwd *ctrlpb.Fwd) string {
+	status := strings.ToLower(strings.TrimPrefix(fwd.Status.String(), "FWD_"))
+	// the time of the first transition into the current status
+	var at int64
+	for i := len(fwd.Transitions) - 1; i >= 0 && fwd.Transitions[i].Status == fwd.Status; i-- {
+		at = fwd.Transitions[i].At
+	}
+	if at > 0 {
+		status += " " + since(at)
+	}
+	if fwd.Addrs.Lazy {
+		return status + " (lazy)"
+	}
+	return status
+}
+
+// since is the time since a transition at unix seconds.
+func since(at int64) string {
+	return time.Since(time.Unix(at, 0)).Round(time.Second).String()
+}
+
+func rtt(nanos int64) string {
+	if nanos <= 0 {
+		return "-"
+	}
+	return time.Duration(nanos).Round(time.Microsecond).String()
+}
+
+// remaining is the time left until a forward expires.
+func remaining(expiresAt int64) string {
+	if expiresAt == 0 {
+		return "-"
+	}
+	left := time.Until(time.Unix(expiresAt, 0))
+	if left <= 0 {
+		return "expired"
+	}
+	return left.Round(time.Second).String()
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/Phillezi/tunman/config"
+	"github.com/Phillezi/tunman/internal/defaults"
 	"github.com/Phillezi/tunman/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -37,6 +38,21 @@ func init() {
 	rootCmd.PersistentFlags().Bool("stacktrace", false, "Show the stack trace in error logs")
 	viper.BindPFlag("stacktrace", rootCmd.PersistentFlags().Lookup("stacktrace"))
 
+	rootCmd.PersistentFlags().String("addr", "", "Address of the daemon, unix:/path or https://host:port to control a remote daemon using mutual TLS (default unix:$XDG_RUNTIME_DIR/tunmand.sock, or "+defaults.SocketPath+" if it is unset)")
+	viper.BindPFlag("addr", rootCmd.PersistentFlags().Lookup("addr"))
+
+	rootCmd.PersistentFlags().String("tls-cert", "", "Client certificate used to connect to a remote daemon")
+	viper.BindPFlag("tls.cert", rootCmd.PersistentFlags().Lookup("tls-cert"))
+
+	rootCmd.PersistentFlags().String("tls-key", "", "Private key of the certificate given by --tls-cert")
+	viper.BindPFlag("tls.key", rootCmd.PersistentFlags().Lookup("tls-key"))
+
+	rootCmd.PersistentFlags().String("tls-ca", "", "CA that signs the certificate of the remote daemon, the system roots are used if empty")
+	viper.BindPFlag("tls.ca", rootCmd.PersistentFlags().Lookup("tls-ca"))
+
+	rootCmd.PersistentFlags().String("tls-server-name", "", "Name to verify the certificate of the remote daemon against, the host of --addr if empty")
+	viper.BindPFlag("tls.server-name", rootCmd.PersistentFlags().Lookup("tls-server-name"))
+
 	rootCmd.AddCommand(versionCmd)
 }
 
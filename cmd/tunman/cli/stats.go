@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Phillezi/tunman/internal/connection"
+	"github.com/Phillezi/tunman/interrupt"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [ids...]",
+	Short: "Show traffic and connection statistics of forwards",
+	Long: `The stats command shows the traffic and connection statistics of forwards, all forwards are shown if no IDs are given.
+Forwards can be referred to by ID, name or unambiguous ID prefix.
+IN is the amount of data sent by the clients of a forward and OUT is the amount of data sent back to them.
+
+This is useful to find stale forwards that are no longer used.`,
+	Example: `tunman stats
+# The command above will show the statistics of all forwards
+
+tunman stats MTdlOTk3NTE4YzVhZTRjYw.YmJlZTA1MzNiOTMwMzEwNQ
+# The command above will show the statistics of the forward with the given ID`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if conn := connection.C(); conn != nil {
+			resp, err := conn.Stats(interrupt.GetInstance().Context(), &ctrlpb.StatsRequest{Ids: args})
+			if err != nil {
+				zap.L().Error("failed to do stats command", zap.Error(err))
+				return
+			}
+			for _, err := range resp.Errors {
+				zap.L().Error("error occurred when doing stats command", zap.Error(fmt.Errorf("%s", err)))
+			}
+			if len(resp.Stats) <= 0 {
+				if len(resp.Errors) == 0 {
+					fmt.Println("no active forwards")
+				}
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tACTIVE\tTOTAL\tIN\tOUT\tDIAL FAILURES\tREJECTED\tLAST ACTIVITY")
+			for _, s := range resp.Stats {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%d\t%d\t%s\n",
+					s.Id, s.ActiveConns, s.TotalConns, humanBytes(s.BytesIn), humanBytes(s.BytesOut), s.DialFailures, s.Rejected, lastActivity(s.LastActivity),
+				)
+			}
+			w.Flush()
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+}
+
+func humanBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
+func lastActivity(unix int64) string {
+	if unix == 0 {
+		return "never"
+	}
+	return time.Since(time.Unix(unix, 0)).Round(time.Second).String() + " ago"
+}
@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"crypto/tls"
+	"fmt"
 	"net/http"
 	_ "net/http/pprof"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Phillezi/tunman/config"
@@ -12,6 +17,7 @@ import (
 	"github.com/Phillezi/tunman/log"
 	"github.com/Phillezi/tunman/pkg/controller"
 	"github.com/Phillezi/tunman/pkg/manager"
+	"github.com/Phillezi/tunman/pkg/metrics"
 	"github.com/Phillezi/tunman/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -46,11 +52,53 @@ var rootCmd = &cobra.Command{
 		man := manager.New()
 		interrupt.GetInstance().AddShutdownHook(func() { zap.L().Info("manager shutdown"); man.Shutdown() })
 
-		go func() {
-			if err := controller.ListenAndServe(utils.Or(defaults.SocketPath), man, nil); err != nil {
-				zap.L().Error("error serving", zap.Error(err))
-			}
-		}()
+		if viper.GetBool("metrics.enabled") {
+			go func() {
+				addr := utils.Or(viper.GetString("metrics.addr"), defaults.DefaultMetricsAddr)
+				zap.L().Info("starting metrics server on " + addr)
+				if err := metrics.ListenAndServe(addr, man); err != nil {
+					zap.L().Error("metrics server error", zap.Error(err))
+				}
+			}()
+		}
+
+		socketMode, err := strconv.ParseUint(viper.GetString("socket.mode"), 8, 32)
+		if err != nil {
+			zap.L().Warn("invalid socket mode, using the default", zap.String("mode", viper.GetString("socket.mode")), zap.Error(err))
+			socketMode = uint64(defaults.DefaultSocketMode)
+		}
+		tlsConfig, err := serverTLSConfig()
+		if err != nil {
+			zap.L().Error("invalid tls config, not serving over tls", zap.Error(err))
+		}
+		identityOwners, err := parseIdentityOwners(viper.GetStringSlice("tls.owners"))
+		if err != nil {
+			zap.L().Fatal("invalid tls owners", zap.Error(err))
+		}
+		opts := controller.ServeOpts{
+			TLSConfig: tlsConfig,
+			Policy: &controller.Policy{
+				AllowedIdentities: viper.GetStringSlice("tls.allow"),
+				IdentityOwners:    identityOwners,
+				AdminIdentities:   viper.GetStringSlice("tls.admins"),
+				AllowedUIDs:       toUint32s(viper.GetIntSlice("socket.allow-uids")),
+				AllowedGIDs:       toUint32s(viper.GetIntSlice("socket.allow-gids")),
+				AdminUIDs:         toUint32s(viper.GetIntSlice("socket.admin-uids")),
+				AdminGIDs:         toUint32s(viper.GetIntSlice("socket.admin-gids")),
+			},
+			SocketMode: os.FileMode(socketMode),
+		}
+		listen := viper.GetStringSlice("listen")
+		if len(listen) == 0 {
+			listen = []string{defaults.Socket()}
+		}
+		for _, addr := range listen {
+			go func() {
+				if err := controller.ListenAndServe(addr, man, opts); err != nil {
+					zap.L().Error("error serving", zap.String("address", addr), zap.Error(err))
+				}
+			}()
+		}
 
 		<-interrupt.GetInstance().Context().Done()
 		interrupt.GetInstance().Wait(5 * time.Second)
@@ -69,14 +117,121 @@ func init() {
 	rootCmd.PersistentFlags().Bool("stacktrace", false, "Show the stack trace in error logs")
 	viper.BindPFlag("stacktrace", rootCmd.PersistentFlags().Lookup("stacktrace"))
 
-	rootCmd.PersistentFlags().Bool("insecure", false, "Dont validate against known_hosts")
+	rootCmd.PersistentFlags().Bool("insecure", false, "Dont validate host keys, overrides StrictHostKeyChecking of the ssh config for all hosts")
 	viper.BindPFlag("insecure", rootCmd.PersistentFlags().Lookup("insecure"))
 
 	rootCmd.PersistentFlags().Bool("pprof", false, "Enable pprof profiling HTTP server")
 	viper.BindPFlag("pprof", rootCmd.PersistentFlags().Lookup("pprof"))
 
+	rootCmd.PersistentFlags().StringSlice("listen", nil, "Addresses to serve the control plane on, unix:/path or https://host:port for remote control using mutual TLS (default unix:$XDG_RUNTIME_DIR/tunmand.sock, or "+defaults.SocketPath+" if it is unset)")
+	viper.BindPFlag("listen", rootCmd.PersistentFlags().Lookup("listen"))
+
+	rootCmd.PersistentFlags().String("socket-mode", fmt.Sprintf("%#o", defaults.DefaultSocketMode), "File mode of the unix sockets of the control plane, in octal")
+	viper.BindPFlag("socket.mode", rootCmd.PersistentFlags().Lookup("socket-mode"))
+
+	rootCmd.PersistentFlags().IntSlice("allow-uid", nil, "Users allowed to use the unix sockets of the control plane besides the user running the daemon, checked by peer credentials, the socket mode must let them connect, only admins may open forwards")
+	viper.BindPFlag("socket.allow-uids", rootCmd.PersistentFlags().Lookup("allow-uid"))
+
+	rootCmd.PersistentFlags().IntSlice("allow-gid", nil, "Groups whose members may use the unix sockets of the control plane, checked by peer credentials, the socket mode must let them connect (e.g. 0660)")
+	viper.BindPFlag("socket.allow-gids", rootCmd.PersistentFlags().Lookup("allow-gid"))
+
+	rootCmd.PersistentFlags().IntSlice("admin-uid", nil, "Users that may see and close the forwards of every user and open forwards with the ssh setup of the user running the daemon, root and the user running the daemon always can")
+	viper.BindPFlag("socket.admin-uids", rootCmd.PersistentFlags().Lookup("admin-uid"))
+
+	rootCmd.PersistentFlags().IntSlice("admin-gid", nil, "Groups whose members may see and close the forwards of every user and open forwards")
+	viper.BindPFlag("socket.admin-gids", rootCmd.PersistentFlags().Lookup("admin-gid"))
+
+	rootCmd.PersistentFlags().String("tls-cert", "", "Certificate of the control plane served over https")
+	viper.BindPFlag("tls.cert", rootCmd.PersistentFlags().Lookup("tls-cert"))
+
+	rootCmd.PersistentFlags().String("tls-key", "", "Private key of the certificate given by --tls-cert")
+	viper.BindPFlag("tls.key", rootCmd.PersistentFlags().Lookup("tls-key"))
+
+	rootCmd.PersistentFlags().String("tls-client-ca", "", "CA that signs the certificates of clients allowed to connect over https")
+	viper.BindPFlag("tls.client-ca", rootCmd.PersistentFlags().Lookup("tls-client-ca"))
+
+	rootCmd.PersistentFlags().StringSlice("tls-allow", nil, "Client certificate identities (common name, DNS name, email or URI) allowed over https, any certificate signed by the client CA if empty")
+	viper.BindPFlag("tls.allow", rootCmd.PersistentFlags().Lookup("tls-allow"))
+
+	rootCmd.PersistentFlags().StringSlice("tls-owner", nil, "Map a client certificate identity to the uid owning the forwards it opens, as identity=uid, other clients act as the user running the daemon")
+	viper.BindPFlag("tls.owners", rootCmd.PersistentFlags().Lookup("tls-owner"))
+
+	rootCmd.PersistentFlags().StringSlice("tls-admin", nil, "Client certificate identities that may see and close the forwards of every user and open forwards")
+	viper.BindPFlag("tls.admins", rootCmd.PersistentFlags().Lookup("tls-admin"))
+
+	rootCmd.PersistentFlags().Bool("metrics", false, "Enable the HTTP server serving Prometheus metrics on /metrics")
+	viper.BindPFlag("metrics.enabled", rootCmd.PersistentFlags().Lookup("metrics"))
+
+	rootCmd.PersistentFlags().String("metrics-addr", defaults.DefaultMetricsAddr, "Address of the metrics HTTP server")
+	viper.BindPFlag("metrics.addr", rootCmd.PersistentFlags().Lookup("metrics-addr"))
+
 	rootCmd.PersistentFlags().String("dbpath", defaults.DefaultDBPath, "Set the path for the db")
 	viper.BindPFlag("dbpath", rootCmd.PersistentFlags().Lookup("dbpath"))
+
+	rootCmd.PersistentFlags().Duration("reconnect-initial-backoff", defaults.DefaultReconnectInitialBackoff, "Initial delay before redialing a lost ssh connection, doubled on every failed attempt")
+	viper.BindPFlag("reconnect-initial-backoff", rootCmd.PersistentFlags().Lookup("reconnect-initial-backoff"))
+
+	rootCmd.PersistentFlags().Duration("reconnect-max-backoff", defaults.DefaultReconnectMaxBackoff, "Maximum delay between attempts to redial a lost ssh connection")
+	viper.BindPFlag("reconnect-max-backoff", rootCmd.PersistentFlags().Lookup("reconnect-max-backoff"))
+
+	rootCmd.PersistentFlags().Duration("keepalive-interval", defaults.DefaultKeepaliveInterval, "Interval between ssh keepalives, 0 disables them (ServerAliveInterval in the ssh config takes priority)")
+	viper.BindPFlag("keepalive.interval", rootCmd.PersistentFlags().Lookup("keepalive-interval"))
+
+	rootCmd.PersistentFlags().Int("keepalive-count-max", defaults.DefaultKeepaliveCountMax, "Number of missed keepalives before a connection is considered dead")
+	viper.BindPFlag("keepalive.count-max", rootCmd.PersistentFlags().Lookup("keepalive-count-max"))
+
+	rootCmd.PersistentFlags().Duration("idle-timeout", defaults.DefaultIdleTimeout, "Close the ssh connection of tunnels with only lazy forwards after being unused this long, 0 keeps it open")
+	viper.BindPFlag("idle-timeout", rootCmd.PersistentFlags().Lookup("idle-timeout"))
+
+	rootCmd.PersistentFlags().StringSlice("host-ca", nil, "Files with CA public keys (authorized_keys format) trusted to sign the host certificates of all hosts, in addition to the @cert-authority lines of known_hosts")
+	viper.BindPFlag("host-keys.cas", rootCmd.PersistentFlags().Lookup("host-ca"))
+
+	rootCmd.PersistentFlags().Duration("key-cache-lifetime", defaults.DefaultKeyCacheLifetime, "Keep the decrypted private keys in memory this long so their passphrases are not asked for again, 0 disables caching")
+	viper.BindPFlag("keys.cache-lifetime", rootCmd.PersistentFlags().Lookup("key-cache-lifetime"))
+
+	rootCmd.PersistentFlags().String("passphrase-command", "", "Shell command printing the passphrase of an encrypted private key, the path of the key is passed as $1 (tried before asking the client and SSH_ASKPASS)")
+	viper.BindPFlag("keys.passphrase-command", rootCmd.PersistentFlags().Lookup("passphrase-command"))
+
+	rootCmd.PersistentFlags().Bool("loopback-only", false, "Only allow forwards to listen on loopback addresses and the addresses given by --allow-bind")
+	viper.BindPFlag("bind.loopback-only", rootCmd.PersistentFlags().Lookup("loopback-only"))
+
+	rootCmd.PersistentFlags().StringSlice("allow-bind", nil, "Non-loopback addresses (IPs or CIDRs) forwards may listen on when --loopback-only is set, use 0.0.0.0 to allow binding all interfaces")
+	viper.BindPFlag("bind.allow", rootCmd.PersistentFlags().Lookup("allow-bind"))
+}
+
+func toUint32s(s []int) []uint32 {
+	out := make([]uint32, 0, len(s))
+	for _, v := range s {
+		out = append(out, uint32(v))
+	}
+	return out
+}
+
+// parseIdentityOwners parses the identity=uid mappings of --tls-owner.
+func parseIdentityOwners(mappings []string) (map[string]uint32, error) {
+	owners := make(map[string]uint32, len(mappings))
+	for _, m := range mappings {
+		// identities such as URIs may contain = themselves
+		i := strings.LastIndexByte(m, '=')
+		if i < 1 {
+			return nil, fmt.Errorf("expected identity=uid, got %q", m)
+		}
+		id, uid := m[:i], m[i+1:]
+		n, err := strconv.ParseUint(uid, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uid of %q: %w", id, err)
+		}
+		owners[id] = uint32(n)
+	}
+	return owners, nil
+}
+
+// serverTLSConfig returns the tls config of the control plane, nil if no certificate is configured.
+func serverTLSConfig() (*tls.Config, error) {
+	if viper.GetString("tls.cert") == "" && viper.GetString("tls.key") == "" {
+		return nil, nil
+	}
+	return controller.ServerTLSConfig(viper.GetString("tls.cert"), viper.GetString("tls.key"), viper.GetString("tls.client-ca"))
 }
 
 func ExecuteE() error {
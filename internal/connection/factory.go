@@ -1,12 +1,15 @@
 package connection
 
 import (
+	"crypto/tls"
+	"strings"
 	"sync"
 
 	"github.com/Phillezi/tunman/internal/defaults"
 	"github.com/Phillezi/tunman/pkg/controller"
 	ctrlpb "github.com/Phillezi/tunman/proto"
 	"github.com/Phillezi/tunman/utils"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
@@ -17,7 +20,17 @@ var (
 
 func C() ctrlpb.TunnelServiceClient {
 	once.Do(func() {
-		ctrl, err := controller.Dial(utils.Or(defaults.SocketPath))
+		addr := utils.Or(viper.GetString("addr"), defaults.Socket())
+		var tlsConfig *tls.Config
+		if strings.HasPrefix(addr, "https:") {
+			var err error
+			tlsConfig, err = controller.ClientTLSConfig(viper.GetString("tls.cert"), viper.GetString("tls.key"), viper.GetString("tls.ca"), viper.GetString("tls.server-name"))
+			if err != nil {
+				zap.L().Error("failed to load tls config", zap.Error(err))
+				return
+			}
+		}
+		ctrl, err := controller.Dial(addr, tlsConfig)
 		if err != nil {
 			zap.L().Error("failed to connect", zap.Error(err))
 			return
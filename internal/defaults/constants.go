@@ -1,8 +1,30 @@
 package defaults
 
+import "time"
+
 const (
 	LockPath           string = "/tmp/tunmand.lock"
 	SocketPath         string = "unix:/tmp/tunmand.sock" //"unix:/var/run/tunmand.sock"
 	DefaultPublishHost string = "0.0.0.0"
 	DefaultDBPath      string = "./state.db"
+	DefaultSocketMode  uint32 = 0600
+
+	// DefaultProxyHost is where proxy forwards listen without a bind address, they are unauthenticated by default
+	DefaultProxyHost string = "127.0.0.1"
+
+	DefaultReconnectInitialBackoff time.Duration = time.Second
+	DefaultReconnectMaxBackoff     time.Duration = time.Minute
+
+	DefaultKeepaliveInterval time.Duration = 30 * time.Second
+	DefaultKeepaliveCountMax int           = 3
+
+	DefaultIdleTimeout time.Duration = 5 * time.Minute
+
+	// DefaultKeyCacheLifetime is how long decrypted private keys are kept in memory
+	DefaultKeyCacheLifetime time.Duration = time.Hour
+
+	DefaultMetricsAddr string = "localhost:9477"
+
+	// DefaultLogBufferSize is how many log entries are kept per tunnel and forward
+	DefaultLogBufferSize int = 500
 )
@@ -0,0 +1,14 @@
+package defaults
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Socket returns the address of the daemon socket, in $XDG_RUNTIME_DIR if it is set and SocketPath otherwise.
+func Socket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return "unix:" + filepath.Join(dir, "tunmand.sock")
+	}
+	return SocketPath
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"fmt"
+	"time"
+)
+
+// untilLayouts are the accepted formats of --until, clock times refer to the next time that clock time occurs.
+var untilLayouts = []string{"15:04", "15:04:05"}
+
+// ParseDeadline returns the deadline of a forward from a ttl or an until time,
+// the zero time is returned if neither is set.
+func ParseDeadline(ttl time.Duration, until string, now time.Time) (time.Time, error) {
+	if ttl < 0 {
+		return time.Time{}, fmt.Errorf("ttl must be positive")
+	}
+	if ttl > 0 && until != "" {
+		return time.Time{}, fmt.Errorf("ttl and until can not be used together")
+	}
+	if ttl > 0 {
+		return now.Add(ttl), nil
+	}
+	if until == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, until); err == nil {
+		if !t.After(now) {
+			return time.Time{}, fmt.Errorf("until %s is in the past", until)
+		}
+		return t, nil
+	}
+	for _, layout := range untilLayouts {
+		t, err := time.ParseInLocation(layout, until, now.Location())
+		if err != nil {
+			continue
+		}
+		deadline := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+		if !deadline.After(now) {
+			deadline = deadline.AddDate(0, 0, 1)
+		}
+		return deadline, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid until %q, expected a clock time like 18:00 or a RFC3339 time", until)
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDeadline(t *testing.T) {
+	zone := time.FixedZone("CET", 60*60)
+	now := time.Date(2026, time.December, 31, 23, 30, 0, 0, zone)
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		until    string
+		want     time.Time
+		wantErrs bool
+	}{
+		{name: "none"},
+		{name: "ttl", ttl: 90 * time.Minute, want: now.Add(90 * time.Minute)},
+		{name: "negative ttl", ttl: -time.Minute, wantErrs: true},
+		{name: "ttl and until", ttl: time.Hour, until: "23:45", wantErrs: true},
+		{name: "later today", until: "23:45", want: time.Date(2026, time.December, 31, 23, 45, 0, 0, zone)},
+		{name: "with seconds", until: "23:30:30", want: time.Date(2026, time.December, 31, 23, 30, 30, 0, zone)},
+		{name: "wraps to tomorrow", until: "00:15", want: time.Date(2027, time.January, 1, 0, 15, 0, 0, zone)},
+		{name: "now wraps to tomorrow", until: "23:30", want: time.Date(2027, time.January, 1, 23, 30, 0, 0, zone)},
+		{name: "rfc3339", until: "2027-01-02T08:00:00Z", want: time.Date(2027, time.January, 2, 8, 0, 0, 0, time.UTC)},
+		{name: "rfc3339 in the past", until: "2026-12-31T22:00:00Z", wantErrs: true},
+		{name: "rfc3339 now", until: now.Format(time.RFC3339), wantErrs: true},
+		{name: "invalid clock time", until: "25:00", wantErrs: true},
+		{name: "invalid", until: "tomorrow", wantErrs: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDeadline(tt.ttl, tt.until, now)
+			if tt.wantErrs {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if tt.until != "" && tt.want.Location() == zone && got.Location() != zone {
+				t.Errorf("got location %v, want %v", got.Location(), zone)
+			}
+		})
+	}
+}
@@ -19,6 +19,14 @@ func ParsePublishes(publishes []string) (map[string]string, error) {
 		if len(parts) < 2 {
 			return nil, fmt.Errorf("invalid publish format, (less than 2 parts)")
 		}
+		if strings.Contains(publish, "/") {
+			local, remote, err := parseSocketPublish(strings.Split(publish, ":"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid publish format %q: %w", publish, err)
+			}
+			localRemoteMap[local] = remote
+			continue
+		}
 		switch len(parts) {
 		case 2:
 			localRemoteMap[fmt.Sprintf("%s:%s", defaults.DefaultPublishHost, parts[0])] = fmt.Sprintf("%s:%s", defaults.DefaultPublishHost, parts[1])
@@ -38,3 +46,79 @@ func ParsePublishes(publishes []string) (map[string]string, error) {
 
 	return localRemoteMap, nil
 }
+
+// parseSocketPublish parses a publish where at least one side is a unix socket path,
+// e.g. /tmp/docker.sock:/var/run/docker.sock, 5432:/var/run/postgresql/.s.PGSQL.5432 or /tmp/app.sock:localhost:8080.
+// Socket paths must be absolute and can not contain ":".
+func parseSocketPublish(parts []string) (string, string, error) {
+	var localParts, remoteParts []string
+	switch {
+	case utils.IsSocketPath(parts[0]):
+		localParts, remoteParts = parts[:1], parts[1:]
+	case utils.IsSocketPath(parts[len(parts)-1]):
+		localParts, remoteParts = parts[:len(parts)-1], parts[len(parts)-1:]
+	default:
+		return "", "", fmt.Errorf("socket paths must be absolute")
+	}
+
+	local, err := parsePublishSide(localParts)
+	if err != nil {
+		return "", "", err
+	}
+	remote, err := parsePublishSide(remoteParts)
+	if err != nil {
+		return "", "", err
+	}
+	return local, remote, nil
+}
+
+// parsePublishSide parses one side of a publish, either a socket path, a port or a host and a port.
+func parsePublishSide(parts []string) (string, error) {
+	switch len(parts) {
+	case 1:
+		if utils.IsSocketPath(parts[0]) {
+			return parts[0], nil
+		}
+		if _, err := utils.ParsePortStrict(parts[0]); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s:%s", defaults.DefaultPublishHost, parts[0]), nil
+	case 2:
+		if _, err := utils.ParsePortStrict(parts[1]); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s:%s", parts[0], parts[1]), nil
+	default:
+		return "", fmt.Errorf("expected <addr>:<port>, <port> or an absolute socket path")
+	}
+}
+
+// ParseListenAddrs parses forwards that only listen locally, like dynamic (SOCKS) and HTTP proxy forwards,
+// of the form [<bind-addr>:]<port> or an absolute socket path into listen addresses.
+// Like ssh -D, forwards without a bind address listen on defaultHost and an empty or "*" bind address listens on all interfaces.
+func ParseListenAddrs(listens []string, defaultHost string) ([]string, error) {
+	if len(listens) == 0 {
+		return nil, nil
+	}
+	addrs := make([]string, 0, len(listens))
+
+	for _, listen := range listens {
+		if utils.IsSocketPath(listen) {
+			addrs = append(addrs, listen)
+			continue
+		}
+		host, port := defaultHost, listen
+		if i := strings.LastIndex(listen, ":"); i >= 0 {
+			host, port = listen[:i], listen[i+1:]
+			if host == "" || host == "*" {
+				host = defaults.DefaultPublishHost
+			}
+		}
+		if _, err := utils.ParsePortStrict(port); err != nil {
+			return nil, fmt.Errorf("invalid listen address %q: %w", listen, err)
+		}
+		addrs = append(addrs, fmt.Sprintf("%s:%s", host, port))
+	}
+
+	return addrs, nil
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestParsePublishes(t *testing.T) {
+	tests := []struct {
+		name     string
+		publish  []string
+		want     map[string]string
+		wantErrs bool
+	}{
+		{name: "none"},
+		{name: "ports", publish: []string{"8080:80"}, want: map[string]string{"0.0.0.0:8080": "0.0.0.0:80"}},
+		{name: "remote host", publish: []string{"5050:10.0.12.1:5050"}, want: map[string]string{"0.0.0.0:5050": "10.0.12.1:5050"}},
+		{name: "bind address", publish: []string{"localhost:4040:4040"}, want: map[string]string{"localhost:4040": "0.0.0.0:4040"}},
+		{name: "both addresses", publish: []string{"127.0.0.1:80:db:5432"}, want: map[string]string{"127.0.0.1:80": "db:5432"}},
+		{
+			name:    "several",
+			publish: []string{"1:2", "3:4"},
+			want:    map[string]string{"0.0.0.0:1": "0.0.0.0:2", "0.0.0.0:3": "0.0.0.0:4"},
+		},
+		{name: "one part", publish: []string{"8080"}, wantErrs: true},
+		{name: "sockets", publish: []string{"/tmp/docker.sock:/var/run/docker.sock"}, want: map[string]string{"/tmp/docker.sock": "/var/run/docker.sock"}},
+		{name: "port to socket", publish: []string{"5432:/var/run/postgresql/.s.PGSQL.5432"}, want: map[string]string{"0.0.0.0:5432": "/var/run/postgresql/.s.PGSQL.5432"}},
+		{name: "socket to host", publish: []string{"/tmp/app.sock:localhost:8080"}, want: map[string]string{"/tmp/app.sock": "localhost:8080"}},
+		{name: "relative socket", publish: []string{"app.sock:/var/run/app.sock"}, wantErrs: true},
+		{name: "no absolute socket", publish: []string{"8080:run/app.sock"}, wantErrs: true},
+		{name: "bad socket port", publish: []string{"/tmp/app.sock:localhost:http"}, wantErrs: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePublishes(tt.publish)
+			if tt.wantErrs {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSocketPublish(t *testing.T) {
+	tests := []struct {
+		parts    []string
+		local    string
+		remote   string
+		wantErrs bool
+	}{
+		{parts: []string{"/tmp/a.sock", "/tmp/b.sock"}, local: "/tmp/a.sock", remote: "/tmp/b.sock"},
+		{parts: []string{"/tmp/a.sock", "8080"}, local: "/tmp/a.sock", remote: "0.0.0.0:8080"},
+		{parts: []string{"/tmp/a.sock", "db", "5432"}, local: "/tmp/a.sock", remote: "db:5432"},
+		{parts: []string{"localhost", "5432", "/run/pg.sock"}, local: "localhost:5432", remote: "/run/pg.sock"},
+		{parts: []string{"a", "b", "5432", "/run/pg.sock"}, wantErrs: true},
+		{parts: []string{"/tmp/a.sock", "db", "port"}, wantErrs: true},
+		{parts: []string{"a.sock", "b.sock"}, wantErrs: true},
+	}
+	for _, tt := range tests {
+		local, remote, err := parseSocketPublish(tt.parts)
+		if tt.wantErrs {
+			if err == nil {
+				t.Errorf("%v: expected an error, got %q -> %q", tt.parts, local, remote)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", tt.parts, err)
+			continue
+		}
+		if local != tt.local || remote != tt.remote {
+			t.Errorf("%v: got %q -> %q, want %q -> %q", tt.parts, local, remote, tt.local, tt.remote)
+		}
+	}
+}
+
+func TestParseListenAddrs(t *testing.T) {
+	tests := []struct {
+		name     string
+		listens  []string
+		want     []string
+		wantErrs bool
+	}{
+		{name: "none"},
+		{name: "port only", listens: []string{"1080"}, want: []string{"127.0.0.1:1080"}},
+		{name: "empty bind address", listens: []string{":1080"}, want: []string{"0.0.0.0:1080"}},
+		{name: "all interfaces", listens: []string{"*:1080"}, want: []string{"0.0.0.0:1080"}},
+		{name: "bind address", listens: []string{"192.168.1.2:3128"}, want: []string{"192.168.1.2:3128"}},
+		{name: "ipv6", listens: []string{"[::1]:1080"}, want: []string{"[::1]:1080"}},
+		{name: "socket", listens: []string{"/tmp/socks.sock"}, want: []string{"/tmp/socks.sock"}},
+		{name: "several", listens: []string{"1080", "localhost:1081"}, want: []string{"127.0.0.1:1080", "localhost:1081"}},
+		{name: "bad port", listens: []string{"socks"}, wantErrs: true},
+		{name: "port out of range", listens: []string{"localhost:70000"}, wantErrs: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseListenAddrs(tt.listens, "127.0.0.1")
+			if tt.wantErrs {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
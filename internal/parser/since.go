@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"fmt"
+	"time"
+)
+
+// ParseSince returns the time given by since, a duration before now like 10m,
+// a RFC3339 time or a clock time that refers to the last time that clock time occurred.
+// The zero time is returned if since is empty.
+func ParseSince(since string, now time.Time) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(since); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("since must be positive")
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	for _, layout := range untilLayouts {
+		t, err := time.ParseInLocation(layout, since, now.Location())
+		if err != nil {
+			continue
+		}
+		start := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+		if start.After(now) {
+			start = start.AddDate(0, 0, -1)
+		}
+		return start, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q, expected a duration like 10m, a clock time like 09:30 or a RFC3339 time", since)
+}
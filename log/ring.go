@@ -0,0 +1,175 @@
+package log
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Phillezi/tunman/internal/defaults"
+	"go.uber.org/zap/zapcore"
+)
+
+// The keys of the fields that tag log entries with the tunnel and forward they belong to.
+const (
+	TunnelKey = "tunnel"
+	FwdKey    = "fwd"
+)
+
+// followBuffer is how many records a follower may lag behind before records are dropped.
+const followBuffer = 256
+
+// Buffers holds the latest tagged log entries of every tunnel and forward.
+var Buffers = NewRing(defaults.DefaultLogBufferSize)
+
+// Record is a log entry of a tunnel or forward.
+type Record struct {
+	Time     time.Time
+	Level    zapcore.Level
+	Message  string
+	TunnelID string
+	FwdID    string
+	Fields   map[string]string
+}
+
+// key is the forward the record belongs to, or the tunnel for entries of the whole tunnel.
+func (r Record) key() string {
+	return cmp.Or(r.FwdID, r.TunnelID)
+}
+
+type follower struct {
+	keys []string
+	ch   chan Record
+}
+
+// Ring keeps the latest size log entries per tunnel and forward.
+type Ring struct {
+	mu        sync.Mutex
+	size      int
+	records   map[string][]Record
+	followers map[*follower]struct{}
+}
+
+func NewRing(size int) *Ring {
+	return &Ring{
+		size:      size,
+		records:   make(map[string][]Record),
+		followers: make(map[*follower]struct{}),
+	}
+}
+
+func (r *Ring) add(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := rec.key()
+	recs := append(r.records[key], rec)
+	if len(recs) > r.size {
+		recs = slices.Delete(recs, 0, len(recs)-r.size)
+	}
+	r.records[key] = recs
+
+	for f := range r.followers {
+		if !slices.Contains(f.keys, key) {
+			continue
+		}
+		select {
+		case f.ch <- rec:
+		default:
+		}
+	}
+}
+
+// Records returns the buffered records of the given tunnels and forwards ordered by time.
+func (r *Ring) Records(keys ...string) []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var recs []Record
+	for _, key := range keys {
+		recs = append(recs, r.records[key]...)
+	}
+	slices.SortStableFunc(recs, func(a, b Record) int { return a.Time.Compare(b.Time) })
+	return recs
+}
+
+// Follow returns the records of the given tunnels and forwards as they are logged,
+// the returned func stops following and must be called once the records are no longer read.
+func (r *Ring) Follow(keys ...string) (<-chan Record, func()) {
+	f := &follower{keys: keys, ch: make(chan Record, followBuffer)}
+	r.mu.Lock()
+	r.followers[f] = struct{}{}
+	r.mu.Unlock()
+
+	var once sync.Once
+	return f.ch, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.followers, f)
+			r.mu.Unlock()
+		})
+	}
+}
+
+// Forget drops the records of the given tunnels and forwards.
+func (r *Ring) Forget(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		delete(r.records, key)
+	}
+}
+
+// Core returns a core that writes the entries tagged with a tunnel or forward to the ring.
+func (r *Ring) Core(level zapcore.LevelEnabler) zapcore.Core {
+	return &ringCore{LevelEnabler: level, ring: r}
+}
+
+type ringCore struct {
+	zapcore.LevelEnabler
+	ring   *Ring
+	fields []zapcore.Field
+}
+
+func (c *ringCore) With(fields []zapcore.Field) zapcore.Core {
+	return &ringCore{LevelEnabler: c.LevelEnabler, ring: c.ring, fields: append(slices.Clip(c.fields), fields...)}
+}
+
+func (c *ringCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *ringCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := append(slices.Clip(c.fields), fields...)
+	if !slices.ContainsFunc(all, func(f zapcore.Field) bool { return f.Key == TunnelKey || f.Key == FwdKey }) {
+		return nil
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range all {
+		f.AddTo(enc)
+	}
+	tunnelID, _ := enc.Fields[TunnelKey].(string)
+	fwdID, _ := enc.Fields[FwdKey].(string)
+	rec := Record{
+		Time:     ent.Time,
+		Level:    ent.Level,
+		Message:  ent.Message,
+		TunnelID: tunnelID,
+		FwdID:    fwdID,
+		Fields:   make(map[string]string, len(enc.Fields)),
+	}
+	for k, v := range enc.Fields {
+		if k != TunnelKey && k != FwdKey {
+			rec.Fields[k] = fmt.Sprint(v)
+		}
+	}
+	c.ring.add(rec)
+	return nil
+}
+
+func (c *ringCore) Sync() error {
+	return nil
+}
@@ -44,7 +44,10 @@ func Setup() (*zap.Logger, error) {
 	cfg.Level = zap.NewAtomicLevelAt(zapLevel)
 	cfg.DisableStacktrace = !showStackTrace
 
-	logger, err := cfg.Build()
+	logger, err := cfg.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		// keep the entries of tunnels and forwards around for tunman logs
+		return zapcore.NewTee(core, Buffers.Core(cfg.Level))
+	}))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,146 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ACL decides which clients may connect to a forward based on their address.
+// Denied prefixes take priority, if there are no allowed prefixes all other clients are allowed.
+type ACL struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// New parses the allow and deny lists, entries are CIDRs or plain IP addresses.
+func New(allow, deny []string) (*ACL, error) {
+	a := &ACL{}
+	var err error
+	if a.allow, err = ParsePrefixes(allow); err != nil {
+		return nil, fmt.Errorf("invalid allow list: %w", err)
+	}
+	if a.deny, err = ParsePrefixes(deny); err != nil {
+		return nil, fmt.Errorf("invalid deny list: %w", err)
+	}
+	return a, nil
+}
+
+// ParsePrefixes parses CIDRs or plain IP addresses, the latter matching only that address.
+// IPv4-mapped IPv6 entries like ::ffff:10.0.0.1 are unmapped, like the addresses of clients.
+func ParsePrefixes(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if strings.Contains(e, "/") {
+			p, err := netip.ParsePrefix(e)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, unmapPrefix(p.Masked()))
+			continue
+		}
+		ip, err := netip.ParseAddr(e)
+		if err != nil {
+			return nil, err
+		}
+		ip = ip.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(ip, ip.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// unmapPrefix returns the IPv4 prefix of a prefix within ::ffff:0:0/96.
+func unmapPrefix(p netip.Prefix) netip.Prefix {
+	if !p.Addr().Is4In6() || p.Bits() < 96 {
+		return p
+	}
+	return netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+}
+
+// Empty reports if the ACL allows everything.
+func (a *ACL) Empty() bool {
+	return a == nil || len(a.allow) == 0 && len(a.deny) == 0
+}
+
+// Allowed reports if a client connecting from addr may use the forward.
+// Clients without an IP address, like unix socket peers, are always allowed.
+func (a *ACL) Allowed(addr net.Addr) bool {
+	if a.Empty() {
+		return true
+	}
+	ip, ok := addrIP(addr)
+	if !ok {
+		return true
+	}
+	if contains(a.deny, ip) {
+		return false
+	}
+	return len(a.allow) == 0 || contains(a.allow, ip)
+}
+
+func addrIP(addr net.Addr) (netip.Addr, bool) {
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		ip, ok := netip.AddrFromSlice(addr.IP)
+		return ip.Unmap(), ok
+	case nil:
+		return netip.Addr{}, false
+	}
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	// prefixes never contain addresses with a zone
+	return ap.Addr().Unmap().WithZone(""), true
+}
+
+func contains(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// BindPolicy decides which local addresses forwards may listen on.
+type BindPolicy struct {
+	// LoopbackOnly forbids binding non-loopback addresses that are not in Allowed.
+	LoopbackOnly bool
+	Allowed      []netip.Prefix
+}
+
+// Check returns an error if the policy forbids listening on the tcp address addr.
+// Hostnames are resolved and every address they resolve to has to be allowed.
+func (p *BindPolicy) Check(ctx context.Context, addr string) error {
+	if p == nil || !p.LoopbackOnly {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	var ips []netip.Addr
+	if host == "" {
+		ips = []netip.Addr{netip.IPv4Unspecified(), netip.IPv6Unspecified()}
+	} else if ip, err := netip.ParseAddr(host); err == nil {
+		ips = []netip.Addr{ip}
+	} else {
+		ips, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return fmt.Errorf("resolve bind address %s: %w", host, err)
+		}
+	}
+
+	for _, ip := range ips {
+		ip = ip.Unmap()
+		if !ip.IsLoopback() && !contains(p.Allowed, ip) {
+			return fmt.Errorf("binding %s is not allowed, only loopback addresses may be bound", addr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package acl
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestParsePrefixes(t *testing.T) {
+	tests := []struct {
+		entry    string
+		want     string
+		wantErrs bool
+	}{
+		{entry: "10.0.0.0/8", want: "10.0.0.0/8"},
+		{entry: "10.1.2.3/8", want: "10.0.0.0/8"},
+		{entry: "10.0.0.1", want: "10.0.0.1/32"},
+		{entry: " 10.0.0.1 ", want: "10.0.0.1/32"},
+		{entry: "::1", want: "::1/128"},
+		{entry: "fd00::/8", want: "fd00::/8"},
+		{entry: "::ffff:10.0.0.1", want: "10.0.0.1/32"},
+		{entry: "::ffff:10.0.0.0/104", want: "10.0.0.0/8"},
+		{entry: "::/0", want: "::/0"},
+		{entry: "example.com", wantErrs: true},
+		{entry: "10.0.0.0/33", wantErrs: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePrefixes([]string{tt.entry})
+		if tt.wantErrs {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", tt.entry, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.entry, err)
+			continue
+		}
+		if want := []netip.Prefix{netip.MustParsePrefix(tt.want)}; !slices.Equal(got, want) {
+			t.Errorf("%q: got %v, want %v", tt.entry, got, want)
+		}
+	}
+}
+
+func tcp(ip string) net.Addr {
+	return &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow []string
+		deny  []string
+		addr  net.Addr
+		want  bool
+	}{
+		{name: "empty", addr: tcp("203.0.113.1"), want: true},
+		{name: "allowed", allow: []string{"10.0.0.0/8"}, addr: tcp("10.1.2.3"), want: true},
+		{name: "not allowed", allow: []string{"10.0.0.0/8"}, addr: tcp("192.168.1.1"), want: false},
+		{name: "denied", deny: []string{"10.0.0.0/8"}, addr: tcp("10.1.2.3"), want: false},
+		{name: "not denied", deny: []string{"10.0.0.0/8"}, addr: tcp("192.168.1.1"), want: true},
+		{name: "deny takes priority", allow: []string{"10.0.0.0/8"}, deny: []string{"10.0.0.5"}, addr: tcp("10.0.0.5"), want: false},
+		{name: "allowed next to denied", allow: []string{"10.0.0.0/8"}, deny: []string{"10.0.0.5"}, addr: tcp("10.0.0.6"), want: true},
+		{name: "plain ip", allow: []string{"10.0.0.1"}, addr: tcp("10.0.0.1"), want: true},
+		{name: "plain ip only", allow: []string{"10.0.0.1"}, addr: tcp("10.0.0.2"), want: false},
+		{name: "mapped peer", allow: []string{"10.0.0.0/8"}, addr: &net.TCPAddr{IP: net.ParseIP("::ffff:10.0.0.1").To16()}, want: true},
+		{name: "mapped entry", allow: []string{"::ffff:10.0.0.1"}, addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1).To4()}, want: true},
+		{name: "mapped denied entry", deny: []string{"::ffff:10.0.0.0/104"}, addr: tcp("10.0.0.1"), want: false},
+		{name: "ipv6", allow: []string{"fd00::/8"}, addr: tcp("fd00::1"), want: true},
+		{name: "ipv6 not allowed", allow: []string{"fd00::/8"}, addr: tcp("2001:db8::1"), want: false},
+		{name: "zoned peer", allow: []string{"fe80::/10"}, addr: &net.UDPAddr{IP: net.ParseIP("fe80::1"), Zone: "eth0", Port: 1}, want: true},
+		{name: "unix peer", allow: []string{"10.0.0.0/8"}, addr: &net.UnixAddr{Name: "@", Net: "unix"}, want: true},
+		{name: "no peer", deny: []string{"0.0.0.0/0"}, addr: nil, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New(tt.allow, tt.deny)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := a.Allowed(tt.addr); got != tt.want {
+				t.Errorf("Allowed(%v) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilACL(t *testing.T) {
+	var a *ACL
+	if !a.Empty() || !a.Allowed(tcp("10.0.0.1")) {
+		t.Error("a nil ACL should allow everything")
+	}
+}
+
+func TestBindPolicy(t *testing.T) {
+	policy := &BindPolicy{LoopbackOnly: true, Allowed: []netip.Prefix{netip.MustParsePrefix("192.168.1.0/24")}}
+	tests := []struct {
+		policy *BindPolicy
+		addr   string
+		want   bool
+	}{
+		{policy: nil, addr: "0.0.0.0:80", want: true},
+		{policy: &BindPolicy{}, addr: "0.0.0.0:80", want: true},
+		{policy: policy, addr: "127.0.0.1:80", want: true},
+		{policy: policy, addr: "[::1]:80", want: true},
+		{policy: policy, addr: "[::ffff:127.0.0.1]:80", want: true},
+		{policy: policy, addr: "192.168.1.10:80", want: true},
+		{policy: policy, addr: "192.168.2.10:80", want: false},
+		{policy: policy, addr: "0.0.0.0:80", want: false},
+		{policy: policy, addr: ":80", want: false},
+		{policy: policy, addr: "127.0.0.1", want: false},
+	}
+	for _, tt := range tests {
+		err := tt.policy.Check(context.Background(), tt.addr)
+		if got := err == nil; got != tt.want {
+			t.Errorf("Check(%q) with %+v = %v, want allowed %v", tt.addr, tt.policy, err, tt.want)
+		}
+	}
+}
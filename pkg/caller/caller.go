@@ -0,0 +1,24 @@
+package caller
+
+import "context"
+
+// Caller is the local user a request to the control server is made by.
+type Caller struct {
+	UID uint32
+	// Admin callers may see and close the forwards of every user, only they and the user
+	// running the daemon may open forwards since tunnels use the ssh setup of the daemon.
+	Admin bool
+}
+
+type ctxKey struct{}
+
+// NewContext returns ctx carrying c.
+func NewContext(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, ctxKey{}, c)
+}
+
+// FromContext returns the caller of ctx, requests made by the daemon itself have none.
+func FromContext(ctx context.Context) (Caller, bool) {
+	c, ok := ctx.Value(ctxKey{}).(Caller)
+	return c, ok
+}
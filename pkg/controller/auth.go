@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+
+	"github.com/Phillezi/tunman/pkg/caller"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Policy decides which clients may use the control server.
+type Policy struct {
+	// AllowedIdentities are the client certificate identities allowed over TLS, a common name,
+	// DNS name, email address or URI of the certificate. Any verified certificate is allowed if empty.
+	AllowedIdentities []string
+	// IdentityOwners maps client certificate identities to the users owning the forwards
+	// they open, the other certificates act as the user running the daemon.
+	IdentityOwners map[string]uint32
+	// AdminIdentities are the client certificate identities that may see and close the forwards of every user
+	// and open forwards for the user they are mapped to.
+	AdminIdentities []string
+	// AllowedUIDs and AllowedGIDs are the users and groups allowed over unix sockets
+	// in addition to the user running the daemon.
+	AllowedUIDs []uint32
+	AllowedGIDs []uint32
+	// AdminUIDs and AdminGIDs are the users and groups that may see and close the forwards
+	// of every user and open forwards, root and the user running the daemon are always admins.
+	AdminUIDs []uint32
+	AdminGIDs []uint32
+}
+
+// authorize checks the client of ctx against the policy, clients connected over TLS are checked
+// by their certificates and clients of unix sockets by their peer credentials.
+// The returned context carries the caller of the client, clients that are not identified by
+// a local user act as the user running the daemon without being admins.
+func (p *Policy) authorize(ctx context.Context) (context.Context, error) {
+	pr, ok := peer.FromContext(ctx)
+	if !ok {
+		if p == nil {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.PermissionDenied, "unknown peer")
+	}
+	c := caller.Caller{UID: uint32(os.Getuid())}
+	switch info := pr.AuthInfo.(type) {
+	case credentials.TLSInfo:
+		if p != nil {
+			var err error
+			if c, err = p.authorizeCert(pr, info); err != nil {
+				return nil, err
+			}
+		}
+	case PeerCred:
+		if p != nil {
+			if err := p.authorizePeer(info); err != nil {
+				return nil, err
+			}
+		}
+		c = caller.Caller{UID: info.UID, Admin: p.isAdmin(info)}
+	default:
+		if _, ok := pr.Addr.(*net.UnixAddr); ok && p.restrictsPeers() {
+			// fail closed when the peer credentials could not be read on this platform
+			zap.L().Warn("rejected unix socket client without peer credentials", zap.Stringer("addr", pr.Addr))
+			return nil, status.Error(codes.PermissionDenied, "peer credentials are unavailable, the allowed users can not be checked")
+		}
+	}
+	return caller.NewContext(ctx, c), nil
+}
+
+// restrictsPeers reports if the policy allows users besides the user running the daemon on unix sockets.
+func (p *Policy) restrictsPeers() bool {
+	return p != nil && (len(p.AllowedUIDs) > 0 || len(p.AllowedGIDs) > 0 || len(p.AdminUIDs) > 0 || len(p.AdminGIDs) > 0)
+}
+
+// checkSocketMode warns when mode keeps the users and groups of the policy from connecting to a unix socket.
+func (p *Policy) checkSocketMode(path string, mode os.FileMode) {
+	if p.restrictsPeers() && mode&0o022 == 0 {
+		zap.L().Warn("the socket mode only lets the user running the daemon connect, the other allowed users and groups can not use the socket",
+			zap.String("path", path), zap.Stringer("mode", mode))
+	}
+}
+
+// authorizeCert checks the client certificate and returns the caller it acts as, the user its identity
+// is mapped to by IdentityOwners or else the user running the daemon.
+func (p *Policy) authorizeCert(pr *peer.Peer, tlsInfo credentials.TLSInfo) (caller.Caller, error) {
+	if len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return caller.Caller{}, status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+	ids := certIdentities(tlsInfo.State.VerifiedChains[0][0])
+	if len(p.AllowedIdentities) > 0 && !slices.ContainsFunc(ids, func(id string) bool { return slices.Contains(p.AllowedIdentities, id) }) {
+		zap.L().Warn("rejected client certificate", zap.Stringer("addr", pr.Addr), zap.Strings("identities", ids))
+		return caller.Caller{}, status.Error(codes.PermissionDenied, "client certificate is not allowed")
+	}
+	c := caller.Caller{UID: uint32(os.Getuid())}
+	for _, id := range ids {
+		if uid, ok := p.IdentityOwners[id]; ok {
+			c.UID = uid
+			break
+		}
+	}
+	c.Admin = slices.ContainsFunc(ids, func(id string) bool { return slices.Contains(p.AdminIdentities, id) })
+	return c, nil
+}
+
+func (p *Policy) authorizePeer(cred PeerCred) error {
+	if slices.Contains(p.AllowedUIDs, cred.UID) || p.isAdmin(cred) || inGroups(cred, p.AllowedGIDs) {
+		return nil
+	}
+	zap.L().Warn("rejected unix socket client", zap.Int32("pid", cred.PID), zap.Uint32("uid", cred.UID), zap.Uint32("gid", cred.GID))
+	return status.Errorf(codes.PermissionDenied, "uid %d is not allowed to use the daemon", cred.UID)
+}
+
+// isAdmin reports if the user of cred may see and close the forwards of every user.
+func (p *Policy) isAdmin(cred PeerCred) bool {
+	if cred.UID == 0 || int(cred.UID) == os.Getuid() {
+		return true
+	}
+	return p != nil && (slices.Contains(p.AdminUIDs, cred.UID) || inGroups(cred, p.AdminGIDs))
+}
+
+// inGroups reports if the user of cred is a member of any of gids.
+func inGroups(cred PeerCred, gids []uint32) bool {
+	if len(gids) == 0 {
+		return false
+	}
+	for _, gid := range peerGroups(cred) {
+		if slices.Contains(gids, gid) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerGroups returns the primary and supplementary groups of the user of cred.
+func peerGroups(cred PeerCred) []uint32 {
+	gids := []uint32{cred.GID}
+	u, err := user.LookupId(strconv.FormatUint(uint64(cred.UID), 10))
+	if err != nil {
+		return gids
+	}
+	groups, err := u.GroupIds()
+	if err != nil {
+		return gids
+	}
+	for _, g := range groups {
+		if gid, err := strconv.ParseUint(g, 10, 32); err == nil {
+			gids = append(gids, uint32(gid))
+		}
+	}
+	return gids
+}
+
+// certIdentities returns the identities of a client certificate.
+func certIdentities(cert *x509.Certificate) []string {
+	var ids []string
+	if cert.Subject.CommonName != "" {
+		ids = append(ids, cert.Subject.CommonName)
+	}
+	ids = append(ids, cert.DNSNames...)
+	ids = append(ids, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		ids = append(ids, u.String())
+	}
+	return ids
+}
+
+func (p *Policy) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := p.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (p *Policy) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := p.authorize(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorizedStream is a stream with the context returned by authorize.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
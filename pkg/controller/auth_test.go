@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/Phillezi/tunman/pkg/caller"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizePeer(t *testing.T) {
+	unixAddr := &net.UnixAddr{Name: "@", Net: "unix"}
+	other := uint32(os.Getuid() + 1000)
+	tests := []struct {
+		name   string
+		policy *Policy
+		addr   net.Addr
+		auth   credentials.AuthInfo
+		want   codes.Code
+	}{
+		{name: "daemon user", policy: &Policy{}, addr: unixAddr, auth: PeerCred{UID: uint32(os.Getuid())}, want: codes.OK},
+		{name: "other user", policy: &Policy{}, addr: unixAddr, auth: PeerCred{UID: other, GID: other}, want: codes.PermissionDenied},
+		{name: "allowed user", policy: &Policy{AllowedUIDs: []uint32{other}}, addr: unixAddr, auth: PeerCred{UID: other, GID: other}, want: codes.OK},
+		{name: "admin user", policy: &Policy{AdminUIDs: []uint32{other}}, addr: unixAddr, auth: PeerCred{UID: other, GID: other}, want: codes.OK},
+		{name: "no peer credentials", policy: &Policy{}, addr: unixAddr, want: codes.OK},
+		{name: "no peer credentials with allowed users", policy: &Policy{AllowedUIDs: []uint32{other}}, addr: unixAddr, want: codes.PermissionDenied},
+		{name: "no peer credentials with admin groups", policy: &Policy{AdminGIDs: []uint32{other}}, addr: unixAddr, want: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tt.addr, AuthInfo: tt.auth})
+			_, err := tt.policy.authorize(ctx)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("got %v (%v), want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func tlsInfo(cn string, dnsNames ...string) credentials.TLSInfo {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}, DNSNames: dnsNames}
+	return credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+}
+
+func TestAuthorizeCaller(t *testing.T) {
+	daemon := uint32(os.Getuid())
+	tcpAddr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 40000}
+	policy := &Policy{
+		IdentityOwners:  map[string]uint32{"alice": 1001, "bob.example.com": 1002},
+		AdminIdentities: []string{"ops"},
+	}
+	tests := []struct {
+		name   string
+		policy *Policy
+		addr   net.Addr
+		auth   credentials.AuthInfo
+		want   caller.Caller
+		code   codes.Code
+	}{
+		{name: "unmapped certificate", policy: policy, addr: tcpAddr, auth: tlsInfo("carol"), want: caller.Caller{UID: daemon}},
+		{name: "mapped common name", policy: policy, addr: tcpAddr, auth: tlsInfo("alice"), want: caller.Caller{UID: 1001}},
+		{name: "mapped dns name", policy: policy, addr: tcpAddr, auth: tlsInfo("", "bob.example.com"), want: caller.Caller{UID: 1002}},
+		{name: "admin certificate", policy: policy, addr: tcpAddr, auth: tlsInfo("ops"), want: caller.Caller{UID: daemon, Admin: true}},
+		{name: "not allowed certificate", policy: &Policy{AllowedIdentities: []string{"alice"}}, addr: tcpAddr, auth: tlsInfo("carol"), code: codes.PermissionDenied},
+		{name: "unverified certificate", policy: policy, addr: tcpAddr, auth: credentials.TLSInfo{}, code: codes.Unauthenticated},
+		{name: "plain tcp", policy: policy, addr: tcpAddr, want: caller.Caller{UID: daemon}},
+		{name: "unix socket without peer credentials", policy: &Policy{}, addr: &net.UnixAddr{Name: "@", Net: "unix"}, want: caller.Caller{UID: daemon}},
+		{name: "peer credentials", policy: &Policy{}, addr: &net.UnixAddr{Name: "@", Net: "unix"}, auth: PeerCred{UID: daemon}, want: caller.Caller{UID: daemon, Admin: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tt.addr, AuthInfo: tt.auth})
+			ctx, err := tt.policy.authorize(ctx)
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("got %v (%v), want %v", got, err, tt.code)
+			}
+			if err != nil {
+				return
+			}
+			c, ok := caller.FromContext(ctx)
+			if !ok || c != tt.want {
+				t.Errorf("got caller %+v (%v), want %+v", c, ok, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,50 @@
 package controller
 
 import (
+	"crypto/tls"
+	"fmt"
+	"net/url"
+
 	ctrlpb "github.com/Phillezi/tunman/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-func Dial(socket string) (ctrlpb.TunnelServiceClient, error) {
-	conn, err := grpc.NewClient(socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// Dial connects to the control server at addr, https addresses are dialed using tlsConfig.
+func Dial(addr string, tlsConfig *tls.Config) (ctrlpb.TunnelServiceClient, error) {
+	target, secure, err := dialTarget(addr)
+	if err != nil {
+		return nil, err
+	}
+	creds := insecure.NewCredentials()
+	if secure {
+		if tlsConfig == nil {
+			return nil, fmt.Errorf("connecting to %s requires a client certificate", addr)
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
 	if err != nil {
 		return nil, err
 	}
 	return ctrlpb.NewTunnelServiceClient(conn), nil
 }
+
+// dialTarget returns the grpc target of addr and if it is served over TLS.
+func dialTarget(addr string) (string, bool, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid address format: %w", err)
+	}
+	switch u.Scheme {
+	case "unix":
+		return addr, false, nil
+	case "tcp":
+		return u.Host, false, nil
+	case "https":
+		return u.Host, true, nil
+	default:
+		return "", false, fmt.Errorf("unsupported scheme: %s", u.Scheme)
+	}
+}
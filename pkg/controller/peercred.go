@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// errPeerCredUnsupported is returned when the peer credentials can not be read on this platform.
+var errPeerCredUnsupported = errors.New("peer credentials are not supported on this platform")
+
+// PeerCred is the identity of the process on the other end of a unix socket.
+type PeerCred struct {
+	credentials.CommonAuthInfo
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+func (PeerCred) AuthType() string {
+	return "peercred"
+}
+
+// peerCredentials are the transport credentials of unix sockets,
+// the connection is left as is and the peer credentials are read from it.
+type peerCredentials struct{}
+
+func (peerCredentials) ClientHandshake(_ context.Context, _ string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return conn, nil, nil
+}
+
+func (peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	cred, err := readPeerCred(conn)
+	if errors.Is(err, errPeerCredUnsupported) {
+		return conn, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	cred.SecurityLevel = credentials.NoSecurity
+	return conn, cred, nil
+}
+
+func (peerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "peercred"}
+}
+
+func (c peerCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (peerCredentials) OverrideServerName(string) error {
+	return nil
+}
@@ -0,0 +1,37 @@
+//go:build darwin || freebsd
+
+package controller
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// readPeerCred reads LOCAL_PEERCRED of a unix socket connection, the pid is not part of it.
+func readPeerCred(conn net.Conn) (PeerCred, error) {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return PeerCred{}, fmt.Errorf("peer credentials require a unix socket, got %T", conn)
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return PeerCred{}, err
+	}
+	var xucred *unix.Xucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		xucred, credErr = unix.GetsockoptXucred(int(fd), unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+	}); err != nil {
+		return PeerCred{}, err
+	}
+	if credErr != nil {
+		return PeerCred{}, fmt.Errorf("failed to read peer credentials: %w", credErr)
+	}
+	if xucred.Ngroups < 1 {
+		return PeerCred{}, fmt.Errorf("peer credentials have no group")
+	}
+	// the first group is the effective group of the peer
+	return PeerCred{UID: xucred.Uid, GID: xucred.Groups[0]}, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd
+
+package controller
+
+import "net"
+
+func readPeerCred(net.Conn) (PeerCred, error) {
+	return PeerCred{}, errPeerCredUnsupported
+}
@@ -8,46 +8,74 @@ import (
 	"os"
 	"runtime"
 
+	"github.com/Phillezi/tunman/internal/defaults"
+	"github.com/Phillezi/tunman/pkg/metrics"
 	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/Phillezi/tunman/utils"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
-func New(man ctrlpb.TunnelServiceServer) *grpc.Server {
-	s := grpc.NewServer()
+func New(man ctrlpb.TunnelServiceServer, policy *Policy, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(policy.unaryInterceptor, metrics.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(policy.streamInterceptor),
+	)
+	s := grpc.NewServer(opts...)
 	ctrlpb.RegisterTunnelServiceServer(s, man)
 	return s
 }
 
-// ListenAndServe sets up and starts the gRPC server based on the given address and TLS config.
-func ListenAndServe(addr string, service ctrlpb.TunnelServiceServer, tlsConfig *tls.Config) error {
+// ServeOpts configures how the control server is served.
+type ServeOpts struct {
+	// TLSConfig is required by the https scheme
+	TLSConfig *tls.Config
+	// Policy authorizes the clients by their certificates or unix peer credentials
+	Policy *Policy
+	// SocketMode is the file mode of unix sockets
+	SocketMode os.FileMode
+}
+
+// ListenAndServe sets up and starts the gRPC server based on the given address and options.
+// Clients of the https scheme are authorized by their certificates and clients of unix sockets
+// by their peer credentials, both using the policy of opts.
+func ListenAndServe(addr string, service ctrlpb.TunnelServiceServer, opts ServeOpts) error {
 	u, err := url.Parse(addr)
 	if err != nil {
 		return fmt.Errorf("invalid address format: %w", err)
 	}
+	if u.Scheme == "https" && opts.TLSConfig == nil {
+		return fmt.Errorf("serving %s requires a TLS config", addr)
+	}
 
-	lis, err := createListener(u)
+	lis, err := createListener(u, opts.SocketMode)
 	if err != nil {
 		return err
 	}
 
-	server := grpc.NewServer()
-	ctrlpb.RegisterTunnelServiceServer(server, service)
+	var serverOpts []grpc.ServerOption
+	switch u.Scheme {
+	case "https":
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(opts.TLSConfig)))
+	case "unix":
+		serverOpts = append(serverOpts, grpc.Creds(peerCredentials{}))
+		opts.Policy.checkSocketMode(u.Path, utils.Or(opts.SocketMode, os.FileMode(defaults.DefaultSocketMode)))
+	case "tcp":
+		zap.L().Warn("the control server is served over plain TCP, anyone who can reach it can control the daemon", zap.String("address", addr))
+	}
+	server := New(service, opts.Policy, serverOpts...)
 
 	zap.L().Info("Ctrl server listening", zap.String("scheme", u.Scheme), zap.String("address", addr))
 
-	if u.Scheme == "https" && tlsConfig != nil {
-		return server.Serve(tls.NewListener(lis, tlsConfig))
-	}
-
 	return server.Serve(lis)
 }
 
 // createListener initializes the appropriate listener based on the URL scheme.
-func createListener(u *url.URL) (net.Listener, error) {
+func createListener(u *url.URL, socketMode os.FileMode) (net.Listener, error) {
 	switch u.Scheme {
 	case "unix":
-		return createUnixListener(u.Path)
+		return createUnixListener(u.Path, socketMode)
 	case "tcp", "https":
 		return createTCPListener(u.Host)
 	default:
@@ -55,13 +83,21 @@ func createListener(u *url.URL) (net.Listener, error) {
 	}
 }
 
-// createUnixListener sets up a Unix domain socket listener.
-func createUnixListener(path string) (net.Listener, error) {
+// createUnixListener sets up a Unix domain socket listener that only allows connections permitted by mode.
+func createUnixListener(path string, mode os.FileMode) (net.Listener, error) {
 	if runtime.GOOS == "windows" {
 		return nil, fmt.Errorf("unix sockets are not supported on Windows")
 	}
 	_ = os.Remove(path) // Remove old socket file if exists
-	return net.Listen("unix", path)
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, utils.Or(mode, os.FileMode(defaults.DefaultSocketMode))); err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return lis, nil
 }
 
 // createTCPListener sets up a TCP listener.
@@ -0,0 +1,78 @@
+package events
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"go.uber.org/zap"
+)
+
+// subscriberBuffer is how many events a subscriber may lag behind before events are dropped.
+const subscriberBuffer = 256
+
+// Filter decides which events a subscriber receives.
+type Filter func(*ctrlpb.Event) bool
+
+type subscriber struct {
+	ch     chan *ctrlpb.Event
+	filter Filter
+}
+
+// Bus fans out events to its subscribers, slow subscribers miss events instead of blocking the publisher.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*subscriber]struct{}
+}
+
+func New() *Bus {
+	return &Bus{subs: make(map[*subscriber]struct{})}
+}
+
+// Publish sends ev to all subscribers whose filter matches it, the time of ev is set if missing.
+func (b *Bus) Publish(ev *ctrlpb.Event) {
+	if b == nil {
+		return
+	}
+	if ev.At == 0 {
+		ev.At = time.Now().UnixNano()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if s.filter != nil && !s.filter(ev) {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			zap.L().Debug("dropped event for slow subscriber", zap.Stringer("type", ev.Type))
+		}
+	}
+}
+
+// Subscribe returns a channel of the events that match filter, a nil filter matches all events.
+// The returned func unsubscribes and must be called once the events are no longer read.
+func (b *Bus) Subscribe(filter Filter) (<-chan *ctrlpb.Event, func()) {
+	s := &subscriber{ch: make(chan *ctrlpb.Event, subscriberBuffer), filter: filter}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, s)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// OfTypes returns a filter matching events of the given types, or all events if no types are given.
+func OfTypes(types ...ctrlpb.EventType) Filter {
+	return func(ev *ctrlpb.Event) bool {
+		return len(types) == 0 || slices.Contains(types, ev.Type)
+	}
+}
@@ -0,0 +1,166 @@
+package httpproxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// hopHeaders are removed when proxying, they only apply to a single connection.
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Proxy is a HTTP/1.1 forward proxy that supports CONNECT tunnelling
+// and plain requests with absolute URIs, all upstream connections are made using Dial.
+type Proxy struct {
+	dial      DialFunc
+	transport *http.Transport
+}
+
+func New(dial DialFunc) *Proxy {
+	return &Proxy{
+		dial: dial,
+		transport: &http.Transport{
+			DialContext:           dial,
+			MaxIdleConns:          16,
+			IdleConnTimeout:       90 * time.Second,
+			ResponseHeaderTimeout: 60 * time.Second,
+		},
+	}
+}
+
+// Close closes the idle upstream connections kept by the proxy.
+func (p *Proxy) Close() {
+	p.transport.CloseIdleConnections()
+}
+
+// ServeConn serves proxy requests on conn until the client is done or ctx is cancelled.
+func (p *Proxy) ServeConn(ctx context.Context, conn net.Conn) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	br := bufio.NewReader(conn)
+	for {
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("read request: %w", err)
+		}
+
+		if req.Method == http.MethodConnect {
+			return p.connect(ctx, conn, br, req)
+		}
+
+		if !req.URL.IsAbs() {
+			writeStatus(conn, http.StatusBadRequest)
+			return fmt.Errorf("request uri %q is not absolute", req.RequestURI)
+		}
+
+		keepAlive, err := p.forward(ctx, conn, req)
+		if err != nil || !keepAlive {
+			return err
+		}
+	}
+}
+
+// connect dials the requested host and pipes the connection to it.
+func (p *Proxy) connect(ctx context.Context, conn net.Conn, br *bufio.Reader, req *http.Request) error {
+	host := req.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "443")
+	}
+
+	upstream, err := p.dial(ctx, "tcp", host)
+	if err != nil {
+		writeStatus(conn, http.StatusBadGateway)
+		return fmt.Errorf("dial %s: %w", host, err)
+	}
+	defer upstream.Close()
+
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		return err
+	}
+
+	// the client may have sent data before receiving our response
+	if n := br.Buffered(); n > 0 {
+		buffered, _ := br.Peek(n)
+		if _, err := upstream.Write(buffered); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		io.Copy(upstream, conn)
+		upstream.Close()
+	}()
+	io.Copy(conn, upstream)
+	return nil
+}
+
+// forward sends a plain proxy request upstream and writes the response back to the client.
+// It reports if the client connection can be reused for another request.
+func (p *Proxy) forward(ctx context.Context, conn net.Conn, req *http.Request) (bool, error) {
+	removeHopHeaders(req.Header)
+	req.RequestURI = ""
+
+	resp, err := p.transport.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		writeStatus(conn, http.StatusBadGateway)
+		return false, fmt.Errorf("round trip %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	chunked := len(resp.TransferEncoding) > 0 && resp.TransferEncoding[0] == "chunked"
+	keepAlive := !req.Close && !resp.Close && (resp.ContentLength >= 0 || chunked)
+
+	removeHopHeaders(resp.Header)
+	if !keepAlive {
+		resp.Close = true
+	}
+	if err := resp.Write(conn); err != nil {
+		return false, err
+	}
+	return keepAlive, nil
+}
+
+func removeHopHeaders(h http.Header) {
+	for _, f := range h.Values("Connection") {
+		for _, name := range strings.Split(f, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				h.Del(name)
+			}
+		}
+	}
+	for _, name := range hopHeaders {
+		h.Del(name)
+	}
+}
+
+func writeStatus(conn net.Conn, code int) {
+	fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nContent-Length: 0\r\nConnection: close\r\n\r\n", code, http.StatusText(code))
+}
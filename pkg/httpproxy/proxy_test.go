@@ -0,0 +1,254 @@
+package httpproxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDialer sends every connection to upstream and records the addresses that were asked for.
+type fakeDialer struct {
+	upstream string
+	mu       sync.Mutex
+	dialed   []string
+}
+
+func (d *fakeDialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	d.mu.Lock()
+	d.dialed = append(d.dialed, addr)
+	d.mu.Unlock()
+	if strings.HasPrefix(addr, "unreachable") {
+		return nil, errors.New("unreachable")
+	}
+	var nd net.Dialer
+	return nd.DialContext(ctx, network, d.upstream)
+}
+
+func (d *fakeDialer) addrs() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.dialed...)
+}
+
+// startProxy serves a proxy on a loopback listener whose connections all go to handler,
+// it returns the address of the proxy and the dialer recording the requested addresses.
+func startProxy(t *testing.T, handler http.Handler) (string, *fakeDialer) {
+	t.Helper()
+	upstream := httptest.NewServer(handler)
+	t.Cleanup(upstream.Close)
+	d := &fakeDialer{upstream: upstream.Listener.Addr().String()}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := New(d.dial)
+	t.Cleanup(func() {
+		cancel()
+		l.Close()
+		p.Close()
+	})
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				p.ServeConn(ctx, conn)
+			}()
+		}
+	}()
+	return l.Addr().String(), d
+}
+
+func proxyClient(addr string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(&url.URL{Scheme: "http", Host: addr})},
+		Timeout:   5 * time.Second,
+	}
+}
+
+func TestForward(t *testing.T) {
+	var got http.Header
+	addr, d := startProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Connection", "X-Hop")
+		w.Header().Set("X-Hop", "1")
+		w.Header().Set("X-End", "1")
+		fmt.Fprint(w, r.URL.Path)
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/path", nil)
+	req.Header.Set("Proxy-Authorization", "Basic secret")
+	req.Header.Set("Connection", "X-Client-Hop")
+	req.Header.Set("X-Client-Hop", "1")
+	req.Header.Set("X-End", "1")
+	resp, err := proxyClient(addr).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK || string(body) != "/path" {
+		t.Errorf("got %d %q, want 200 /path", resp.StatusCode, body)
+	}
+	if dialed := d.addrs(); len(dialed) != 1 || dialed[0] != "example.com:80" {
+		t.Errorf("dialed %v, want [example.com:80]", dialed)
+	}
+	for _, h := range []string{"Proxy-Authorization", "X-Client-Hop"} {
+		if got.Get(h) != "" {
+			t.Errorf("hop-by-hop request header %s was forwarded", h)
+		}
+	}
+	if got.Get("X-End") == "" {
+		t.Error("end-to-end request header was not forwarded")
+	}
+	if resp.Header.Get("X-Hop") != "" {
+		t.Error("hop-by-hop response header was forwarded")
+	}
+	if resp.Header.Get("X-End") == "" {
+		t.Error("end-to-end response header was not forwarded")
+	}
+}
+
+func TestForwardKeepAlive(t *testing.T) {
+	addr, _ := startProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	br := bufio.NewReader(conn)
+	for i := range 2 {
+		fmt.Fprint(conn, "GET http://example.com/ HTTP/1.1\r\nHost: example.com\r\n\r\n")
+		resp, err := http.ReadResponse(br, nil)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "ok" {
+			t.Fatalf("request %d: got %q", i, body)
+		}
+	}
+}
+
+func TestConnect(t *testing.T) {
+	tests := []struct {
+		authority string
+		want      string
+	}{
+		{authority: "example.com:8443", want: "example.com:8443"},
+		{authority: "example.com", want: "example.com:443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.authority, func(t *testing.T) {
+			addr, d := startProxy(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "tunnelled")
+			}))
+
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+			br := bufio.NewReader(conn)
+
+			fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", tt.authority, tt.authority)
+			resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("got %s, want 200", resp.Status)
+			}
+			if dialed := d.addrs(); len(dialed) != 1 || dialed[0] != tt.want {
+				t.Errorf("dialed %v, want [%s]", dialed, tt.want)
+			}
+
+			// the tunnel is a plain byte stream to the upstream
+			fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n")
+			resp, err = http.ReadResponse(br, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(body) != "tunnelled" {
+				t.Errorf("got %q through the tunnel", body)
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		want    int
+	}{
+		{name: "relative uri", request: "GET /path HTTP/1.1\r\nHost: example.com\r\n\r\n", want: http.StatusBadRequest},
+		{name: "forward dial failure", request: "GET http://unreachable/ HTTP/1.1\r\nHost: unreachable\r\n\r\n", want: http.StatusBadGateway},
+		{name: "connect dial failure", request: "CONNECT unreachable:443 HTTP/1.1\r\nHost: unreachable:443\r\n\r\n", want: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, _ := startProxy(t, http.NotFoundHandler())
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+			fmt.Fprint(conn, tt.request)
+			resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("got %d, want %d", resp.StatusCode, tt.want)
+			}
+			if !resp.Close {
+				t.Error("the connection should be closed after an error")
+			}
+		})
+	}
+}
+
+func TestRemoveHopHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Add("Connection", "X-A, x-b")
+	h.Add("Connection", "keep-alive")
+	h.Set("X-A", "1")
+	h.Set("X-B", "1")
+	h.Set("Keep-Alive", "timeout=5")
+	h.Set("Transfer-Encoding", "chunked")
+	h.Set("Upgrade", "websocket")
+	h.Set("X-Keep", "1")
+
+	removeHopHeaders(h)
+	if len(h) != 1 || h.Get("X-Keep") != "1" {
+		t.Errorf("got %v, want only X-Keep", h)
+	}
+}
@@ -0,0 +1,157 @@
+package labels
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	keyPattern   = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_./-]*$`)
+	valuePattern = regexp.MustCompile(`^[a-zA-Z0-9_./-]*$`)
+)
+
+// Parse parses key=value pairs into a label map.
+func Parse(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		key, value, ok := strings.Cut(p, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", p)
+		}
+		if err := validate(key, value); err != nil {
+			return nil, err
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// Validate checks that all keys and values of labels are valid.
+func Validate(labels map[string]string) error {
+	for k, v := range labels {
+		if err := validate(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validate(key, value string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	if !valuePattern.MatchString(value) {
+		return fmt.Errorf("invalid label value %q of key %q", value, key)
+	}
+	return nil
+}
+
+// Merge returns the labels of all maps, later maps take priority.
+func Merge(labels ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, l := range labels {
+		maps.Copy(merged, l)
+	}
+	return merged
+}
+
+// String formats labels as sorted comma separated key=value pairs.
+func String(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+type op int
+
+const (
+	opEquals op = iota
+	opNotEquals
+	opExists
+	opNotExists
+)
+
+type requirement struct {
+	key   string
+	op    op
+	value string
+}
+
+// Selector selects labels using comma separated requirements,
+// key=value, key!=value, key (the key is set) and !key (the key is not set).
+// All requirements have to match, an empty selector matches everything.
+type Selector []requirement
+
+// ParseSelector parses a selector like "env=prod,project!=billing,!temporary".
+func ParseSelector(selector string) (Selector, error) {
+	var s Selector
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var r requirement
+		switch {
+		case strings.Contains(part, "!="):
+			r.key, r.value, _ = strings.Cut(part, "!=")
+			r.op = opNotEquals
+		case strings.Contains(part, "="):
+			r.key, r.value, _ = strings.Cut(part, "=")
+			r.key = strings.TrimSuffix(r.key, "=") // allow ==
+			r.value = strings.TrimPrefix(r.value, "=")
+			r.op = opEquals
+		case strings.HasPrefix(part, "!"):
+			r.key = strings.TrimPrefix(part, "!")
+			r.op = opNotExists
+		default:
+			r.key = part
+			r.op = opExists
+		}
+		r.key, r.value = strings.TrimSpace(r.key), strings.TrimSpace(r.value)
+		if err := validate(r.key, r.value); err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", part, err)
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// Empty reports if the selector matches everything.
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+// Matches reports if labels fulfill all requirements of the selector.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		v, ok := labels[r.key]
+		switch r.op {
+		case opEquals:
+			if !ok || v != r.value {
+				return false
+			}
+		case opNotEquals:
+			if ok && v == r.value {
+				return false
+			}
+		case opExists:
+			if !ok {
+				return false
+			}
+		case opNotExists:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,209 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/Phillezi/tunman/pkg/labels"
+	"github.com/Phillezi/tunman/pkg/ser"
+	"github.com/Phillezi/tunman/pkg/tunnel"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// applyCloseWait is how long a replaced forward may take to close before it is opened again.
+const applyCloseWait = 5 * time.Second
+
+type desiredFwd struct {
+	remote tunnel.ConnOpts
+	ap     tunnel.AddressPair
+}
+
+// Apply converges the forwards owned by req.Owner to the forwards in req.Tunnels,
+// missing forwards are opened, changed ones are replaced and the ones that are no longer wanted are closed.
+// Forwards with another owner, or of another user than the caller, are never touched.
+// With DryRun set only the planned changes are returned.
+func (m *Manager) Apply(ctx context.Context, req *ctrlpb.ApplyRequest) (*ctrlpb.ApplyResponse, error) {
+	var changes []*ctrlpb.ApplyChange
+	var errors []string = make([]string, 0)
+
+	if req.Owner == "" {
+		return &ctrlpb.ApplyResponse{Errors: []string{"apply requires an owner"}}, nil
+	}
+
+	uid, err := m.ownerOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	desired := make(map[string]desiredFwd)
+	for _, tf := range req.Tunnels {
+		remote := tunnel.ConnOpts{
+			User:     tf.User,
+			Host:     tf.Host,
+			Port:     uint(tf.Port),
+			Opts:     tunnel.WithProtoOpts(tf.Pw, tf.Privkey),
+			Labels:   tf.Labels,
+			OwnerUID: uid,
+		}
+		for _, fw := range tf.AddressPair {
+			ap := tunnel.AddrPairFromProto(fw)
+			ap.Owner = req.Owner
+			desired[ser.Ser(remote.Hash(), ap.Hash())] = desiredFwd{remote: remote, ap: ap}
+		}
+	}
+
+	// not from Ps, the SOCKS passwords of its forwards are redacted and would never equal the desired ones
+	running := make(map[string]*ctrlpb.Fwd)
+	for _, fwd := range m.listFwds(scope{uid: uid}, labels.Selector{}) {
+		running[fwd.Id] = fwd
+	}
+	// persisted forwards that are not running, e.g. because they failed to open on startup
+	stored := make(map[string]*ctrlpb.FwdState)
+	if m.db != nil {
+		fwds, err := m.db.LoadFwds(uid)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("failed to load persisted fwds: %s", err.Error()))
+		}
+		for _, fwd := range fwds {
+			if _, ok := running[fwd.Id]; !ok {
+				stored[fwd.Id] = fwd
+			}
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(desired)) {
+		want := desired[id]
+		addrs := want.ap.Proto()
+		change := &ctrlpb.ApplyChange{Id: id, Host: want.remote.Host, Addrs: &addrs}
+
+		if cur, ok := running[id]; ok {
+			if cur.Addrs.GetOwner() != req.Owner {
+				errors = append(errors, fmt.Sprintf("fwd %s already exists and is not owned by %s", id, req.Owner))
+				continue
+			}
+			if proto.Equal(cur.Addrs, &addrs) {
+				change.Action = ctrlpb.ApplyAction_APPLY_UNCHANGED
+			} else {
+				change.Action = ctrlpb.ApplyAction_APPLY_REPLACE
+			}
+		} else if st, ok := stored[id]; ok && st.Addrs.GetOwner() != req.Owner {
+			errors = append(errors, fmt.Sprintf("fwd %s already exists and is not owned by %s", id, req.Owner))
+			continue
+		} else {
+			change.Action = ctrlpb.ApplyAction_APPLY_OPEN
+		}
+		changes = append(changes, change)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(running)) {
+		if fwd := running[id]; fwd.Addrs.GetOwner() == req.Owner {
+			if _, ok := desired[id]; !ok {
+				changes = append(changes, &ctrlpb.ApplyChange{Id: id, Action: ctrlpb.ApplyAction_APPLY_CLOSE, Host: fwd.Parent.GetHost(), Addrs: fwd.Addrs})
+			}
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(stored)) {
+		if fwd := stored[id]; fwd.Addrs.GetOwner() == req.Owner {
+			if _, ok := desired[id]; !ok {
+				changes = append(changes, &ctrlpb.ApplyChange{Id: id, Action: ctrlpb.ApplyAction_APPLY_CLOSE, Host: fwd.Host, Addrs: fwd.Addrs})
+			}
+		}
+	}
+
+	if req.DryRun {
+		return &ctrlpb.ApplyResponse{Changes: redactChanges(changes), Errors: errors}, nil
+	}
+
+	for _, change := range changes {
+		var err error
+		switch change.Action {
+		case ctrlpb.ApplyAction_APPLY_OPEN:
+			want := desired[change.Id]
+			err = m.Forward(want.remote, want.ap)
+		case ctrlpb.ApplyAction_APPLY_REPLACE:
+			want := desired[change.Id]
+			if err = m.closeOwned(ctx, change.Id, true); err == nil {
+				err = m.Forward(want.remote, want.ap)
+			}
+		case ctrlpb.ApplyAction_APPLY_CLOSE:
+			_, isRunning := running[change.Id]
+			err = m.closeOwned(ctx, change.Id, isRunning)
+		}
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("failed to %s fwd %s: %s", actionName(change.Action), change.Id, err.Error()))
+			continue
+		}
+		if change.Action != ctrlpb.ApplyAction_APPLY_UNCHANGED {
+			zap.L().Info("applied fwd change", zap.String("id", change.Id), zap.String("action", change.Action.String()), zap.String("owner", req.Owner))
+		}
+	}
+
+	return &ctrlpb.ApplyResponse{Changes: redactChanges(changes), Errors: errors}, nil
+}
+
+// redactChanges removes the secrets of the forwards of changes before they are sent to the client.
+func redactChanges(changes []*ctrlpb.ApplyChange) []*ctrlpb.ApplyChange {
+	for _, c := range changes {
+		c.Addrs = redactAddrs(c.Addrs)
+	}
+	return changes
+}
+
+// closeOwned closes a forward and waits for it to be gone,
+// forwards that are only persisted are deleted from the db.
+func (m *Manager) closeOwned(ctx context.Context, id string, isRunning bool) error {
+	if !isRunning {
+		if m.db == nil {
+			return nil
+		}
+		_, err := m.db.DeleteFwd(id)
+		return err
+	}
+
+	resp, _ := m.CloseFwd(ctx, &ctrlpb.CloseRequest{Ids: []string{id}})
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("%s", resp.Errors[0])
+	}
+	return m.waitClosed(ctx, id)
+}
+
+// waitClosed waits until a closed forward has been removed from its tunnel.
+func (m *Manager) waitClosed(ctx context.Context, id string) error {
+	tunHash, fwdID, err := ser.DeSer(id)
+	if err != nil {
+		return err
+	}
+	timeout := time.After(applyCloseWait)
+	for {
+		m.mu.RLock()
+		t, ok := m.tunnels[tunHash]
+		m.mu.RUnlock()
+		if !ok || !t.Exists(fwdID) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for fwd to close")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func actionName(action ctrlpb.ApplyAction) string {
+	switch action {
+	case ctrlpb.ApplyAction_APPLY_OPEN:
+		return "open"
+	case ctrlpb.ApplyAction_APPLY_CLOSE:
+		return "close"
+	case ctrlpb.ApplyAction_APPLY_REPLACE:
+		return "replace"
+	default:
+		return "keep"
+	}
+}
@@ -0,0 +1,97 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/Phillezi/tunman/pkg/ser"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"go.uber.org/zap"
+)
+
+// expiryCheckInterval is how often forwards are checked for expiry.
+const expiryCheckInterval = time.Second
+
+// expired reports if a forward with the deadline expiresAt (unix time, 0 if none) has expired.
+func expired(expiresAt int64, now time.Time) bool {
+	return expiresAt != 0 && !time.Unix(expiresAt, 0).After(now)
+}
+
+// splitExpired splits persisted forwards into those still to be restored and those that expired at now.
+func splitExpired(fwds []*ctrlpb.FwdState, now time.Time) (live, dead []*ctrlpb.FwdState) {
+	for _, fwd := range fwds {
+		if expired(fwd.ExpiresAt, now) {
+			dead = append(dead, fwd)
+		} else {
+			live = append(live, fwd)
+		}
+	}
+	return live, dead
+}
+
+// expireLoop closes and deletes forwards once they expire.
+func (m *Manager) expireLoop(ctx context.Context) {
+	ticker := time.NewTicker(expiryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ids := m.expiredFwds(now)
+			if len(ids) == 0 {
+				continue
+			}
+
+			resp, _ := m.CloseFwd(ctx, &ctrlpb.CloseRequest{Ids: ids})
+			for _, id := range resp.ClosedIds {
+				zap.L().Info("closed expired fwd", zap.String("id", id))
+			}
+			for _, err := range resp.Errors {
+				zap.L().Warn("failed to close expired fwd", zap.String("error", err))
+			}
+		}
+	}
+}
+
+// expiredFwds returns the ids of the forwards that expired at now, the running ones as well as
+// the failed ones and the persisted ones that are not running.
+func (m *Manager) expiredFwds(now time.Time) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	m.mu.RLock()
+	for tunHash, t := range m.tunnels {
+		for _, id := range t.Expired(now) {
+			add(ser.Ser(tunHash, id))
+		}
+	}
+	m.mu.RUnlock()
+
+	m.entriesMu.Lock()
+	for id, e := range m.entries {
+		if !e.ap.ExpiresAt.IsZero() && !e.ap.ExpiresAt.After(now) {
+			add(id)
+		}
+	}
+	m.entriesMu.Unlock()
+
+	if m.db != nil {
+		stored, err := m.db.LoadAllFwds()
+		if err != nil {
+			zap.L().Warn("failed to load persisted fwds", zap.Error(err))
+		}
+		_, dead := splitExpired(stored, now)
+		for _, fwd := range dead {
+			add(fwd.Id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Phillezi/tunman/pkg/repo"
+	"github.com/Phillezi/tunman/pkg/ser"
+	"github.com/Phillezi/tunman/pkg/tunnel"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+)
+
+func TestExpired(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	tests := []struct {
+		expiresAt int64
+		want      bool
+	}{
+		{expiresAt: 0, want: false},
+		{expiresAt: now.Unix() + 1, want: false},
+		{expiresAt: now.Unix(), want: true},
+		{expiresAt: now.Unix() - 1, want: true},
+	}
+	for _, tt := range tests {
+		if got := expired(tt.expiresAt, now); got != tt.want {
+			t.Errorf("expired(%d) = %v, want %v", tt.expiresAt, got, tt.want)
+		}
+	}
+}
+
+func TestSplitExpired(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	fwds := []*ctrlpb.FwdState{
+		{Id: "forever"},
+		{Id: "later", ExpiresAt: now.Add(time.Hour).Unix()},
+		{Id: "while-down", ExpiresAt: now.Add(-time.Hour).Unix()},
+		{Id: "now", ExpiresAt: now.Unix()},
+	}
+
+	live, dead := splitExpired(fwds, now)
+	ids := func(fwds []*ctrlpb.FwdState) []string {
+		var ids []string
+		for _, f := range fwds {
+			ids = append(ids, f.Id)
+		}
+		return ids
+	}
+	if got := ids(live); !slices.Equal(got, []string{"forever", "later"}) {
+		t.Errorf("restored %v, want [forever later]", got)
+	}
+	if got := ids(dead); !slices.Equal(got, []string{"while-down", "now"}) {
+		t.Errorf("deleted %v, want [while-down now]", got)
+	}
+}
+
+func TestExpireInactive(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	db, err := repo.OpenDB(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	m := &Manager{uid: 1000, db: db, tunnels: make(map[string]*WTunnel), entries: make(map[string]*fwdEntry)}
+
+	failed := func(id string, expiresAt time.Time) {
+		lc := tunnel.NewLifecycle(id)
+		lc.Set(ctrlpb.FwdStatus_FWD_FAILED, errors.New("connection refused"))
+		m.track(id, &fwdEntry{remote: tunnel.ConnOpts{OwnerUID: 1000}, ap: tunnel.AddressPair{ExpiresAt: expiresAt}, lc: lc})
+	}
+	stored := func(id string, owner uint32, expiresAt time.Time) {
+		if err := db.SaveFwd(&ctrlpb.FwdState{Id: id, OwnerUid: owner, ExpiresAt: expiresAt.Unix()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	failedExpired, failedLive := ser.Ser("t1", "a"), ser.Ser("t1", "b")
+	storedExpired, storedLive, otherExpired := ser.Ser("t2", "a"), ser.Ser("t2", "b"), ser.Ser("t3", "a")
+	failed(failedExpired, now.Add(-time.Minute))
+	failed(failedLive, now.Add(time.Minute))
+	stored(storedExpired, 1000, now)
+	stored(storedLive, 1000, now.Add(time.Minute))
+	stored(otherExpired, 1001, now.Add(-time.Hour))
+
+	ids := m.expiredFwds(now)
+	slices.Sort(ids)
+	want := []string{failedExpired, storedExpired, otherExpired}
+	slices.Sort(want)
+	if !slices.Equal(ids, want) {
+		t.Fatalf("expired %v, want %v", ids, want)
+	}
+
+	resp, err := m.CloseFwd(context.Background(), &ctrlpb.CloseRequest{Ids: ids})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed := slices.Sorted(slices.Values(resp.ClosedIds)); !slices.Equal(closed, want) {
+		t.Errorf("closed %v (errors %v), want %v", closed, resp.Errors, want)
+	}
+	if _, ok := m.entry(failedExpired); ok {
+		t.Error("expired failed fwd is still tracked")
+	}
+	if _, ok := m.entry(failedLive); !ok {
+		t.Error("failed fwd that has not expired was dropped")
+	}
+	if left := m.expiredFwds(now); len(left) != 0 {
+		t.Errorf("still expired after closing: %v", left)
+	}
+	fwds, err := db.LoadAllFwds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fwds) != 1 || fwds[0].Id != storedLive {
+		t.Errorf("persisted fwds left %v, want only %s", fwds, storedLive)
+	}
+}
@@ -0,0 +1,136 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Phillezi/tunman/pkg/tunnel"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errNoClient is returned to ssh servers that prompt after the client that opened the tunnel is gone,
+// for example when a tunnel that was authenticated with a one-time password reconnects.
+var errNoClient = errors.New("the client that opened the tunnel is gone, there is no one to answer the prompt")
+
+type openStream = grpc.BidiStreamingServer[ctrlpb.OpenInteractiveRequest, ctrlpb.OpenInteractiveResponse]
+
+// OpenFwdInteractive opens forwards like OpenFwd, the prompts of the ssh servers for passwords and
+// keyboard-interactive challenges are sent to the client and answered by it while the forwards are opened.
+func (m *Manager) OpenFwdInteractive(stream openStream) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	req := first.GetOpen()
+	if req == nil {
+		return status.Error(codes.InvalidArgument, "the first message has to be the forwards to open")
+	}
+
+	relay := newPromptRelay(stream)
+	go relay.readAnswers()
+	defer relay.close()
+
+	resp, err := m.openFwds(stream.Context(), req, tunnel.WithPrompter(relay.prompt))
+	if err != nil {
+		return err
+	}
+	return relay.send(&ctrlpb.OpenInteractiveResponse{Msg: &ctrlpb.OpenInteractiveResponse_Result{Result: resp}})
+}
+
+// promptRelay sends prompts to the client of an interactive open and waits for its answers.
+type promptRelay struct {
+	stream openStream
+	sendMu sync.Mutex
+
+	// mu serializes the prompts, the client answers one at a time
+	mu      sync.Mutex
+	nextID  uint32
+	answers chan *ctrlpb.PromptAnswer
+
+	// done is closed once the client can no longer answer
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newPromptRelay(stream openStream) *promptRelay {
+	return &promptRelay{
+		stream:  stream,
+		answers: make(chan *ctrlpb.PromptAnswer),
+		done:    make(chan struct{}),
+	}
+}
+
+func (r *promptRelay) send(resp *ctrlpb.OpenInteractiveResponse) error {
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+	return r.stream.Send(resp)
+}
+
+// readAnswers passes the answers of the client to the waiting prompt until the client goes away.
+func (r *promptRelay) readAnswers() {
+	defer r.close()
+	for {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return
+		}
+		answer := msg.GetAnswer()
+		if answer == nil {
+			continue
+		}
+		select {
+		case r.answers <- answer:
+		case <-r.done:
+			return
+		}
+	}
+}
+
+func (r *promptRelay) close() {
+	r.closeOnce.Do(func() { close(r.done) })
+}
+
+// prompt asks the client to answer c, it is used as the tunnel.Prompter of the opened tunnels.
+func (r *promptRelay) prompt(c tunnel.Challenge) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-r.done:
+		return nil, errNoClient
+	default:
+	}
+
+	r.nextID++
+	id := r.nextID
+	if err := r.send(&ctrlpb.OpenInteractiveResponse{Msg: &ctrlpb.OpenInteractiveResponse_Prompt{Prompt: &ctrlpb.Prompt{
+		Id:          id,
+		User:        c.User,
+		Host:        c.Host,
+		Name:        c.Name,
+		Instruction: c.Instruction,
+		Questions:   c.Questions,
+		Echos:       c.Echos,
+	}}}); err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case <-r.done:
+			return nil, errNoClient
+		case answer := <-r.answers:
+			if answer.Id != id {
+				// an answer to an earlier prompt that was given up on
+				continue
+			}
+			if answer.Cancel {
+				return nil, fmt.Errorf("prompt for %s@%s was canceled", c.User, c.Host)
+			}
+			return answer.Answers, nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/Phillezi/tunman/log"
+	"github.com/Phillezi/tunman/pkg/ser"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Logs streams the buffered log entries of a forward and of its tunnel,
+// with Follow set new entries are streamed until the client goes away.
+func (m *Manager) Logs(req *ctrlpb.LogsRequest, stream grpc.ServerStreamingServer[ctrlpb.LogEntry]) error {
+	sc, err := m.scopeOf(stream.Context(), false)
+	if err != nil {
+		return err
+	}
+	id, err := m.resolve(sc, req.Id)
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	tunHash, _, err := ser.DeSer(id)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	since := time.Unix(0, req.Since)
+
+	// follow before reading the buffer so that no entries are missed in between
+	var follow <-chan log.Record
+	if req.Follow {
+		recs, stop := log.Buffers.Follow(id, tunHash)
+		defer stop()
+		follow = recs
+	}
+
+	var last time.Time
+	for _, rec := range log.Buffers.Records(id, tunHash) {
+		if rec.Time.Before(since) {
+			continue
+		}
+		if err := stream.Send(logEntry(rec)); err != nil {
+			return err
+		}
+		last = rec.Time
+	}
+	if !req.Follow {
+		return nil
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case rec := <-follow:
+			if !rec.Time.After(last) || rec.Time.Before(since) {
+				// already sent from the buffer
+				continue
+			}
+			if err := stream.Send(logEntry(rec)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func logEntry(rec log.Record) *ctrlpb.LogEntry {
+	return &ctrlpb.LogEntry{
+		At:       rec.Time.UnixNano(),
+		Level:    rec.Level.String(),
+		Message:  rec.Message,
+		TunnelId: rec.TunnelID,
+		FwdId:    rec.FwdID,
+		Fields:   rec.Fields,
+	}
+}
@@ -3,11 +3,17 @@ package manager
 import (
 	"context"
 	"fmt"
+	"os"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/Phillezi/tunman/internal/defaults"
 	"github.com/Phillezi/tunman/interrupt"
+	"github.com/Phillezi/tunman/log"
+	"github.com/Phillezi/tunman/pkg/acl"
+	"github.com/Phillezi/tunman/pkg/events"
+	"github.com/Phillezi/tunman/pkg/labels"
 	"github.com/Phillezi/tunman/pkg/repo"
 	"github.com/Phillezi/tunman/pkg/ser"
 	"github.com/Phillezi/tunman/pkg/tunnel"
@@ -23,7 +29,18 @@ type Manager struct {
 	tunnels map[string]*WTunnel
 	mu      sync.RWMutex
 
+	// uid is the user running the daemon
+	uid uint32
+
 	db *repo.Repo
+
+	bindPolicy *acl.BindPolicy
+
+	events *events.Bus
+
+	// entries holds the status of the forwards opened by the manager
+	entries   map[string]*fwdEntry
+	entriesMu sync.Mutex
 }
 
 type WTunnel struct {
@@ -38,11 +55,17 @@ func New() *Manager {
 	}
 
 	m := &Manager{
-		ctx:     context.Background(),
-		tunnels: make(map[string]*WTunnel),
-		db:      r,
+		ctx:        context.Background(),
+		tunnels:    make(map[string]*WTunnel),
+		uid:        uint32(os.Getuid()),
+		db:         r,
+		bindPolicy: newBindPolicy(),
+		entries:    make(map[string]*fwdEntry),
+		events:     events.New(),
 	}
 
+	go m.expireLoop(interrupt.GetInstance().Context())
+
 	if r != nil {
 		interrupt.GetInstance().AddShutdownHook(func() { r.Close() })
 
@@ -55,16 +78,31 @@ func New() *Manager {
 		}
 		zap.L().Info("loaded state in", zap.Duration("loadTime", end.Sub(start)))
 
+		fwds, dead := splitExpired(fwds, start)
+		for _, fwd := range dead {
+			zap.L().Info("deleting fwd that expired while the daemon was down", zap.String("id", fwd.Id))
+			if _, err := r.DeleteFwd(fwd.Id); err != nil {
+				zap.L().Warn("failed to delete expired fwd", zap.Error(err))
+			}
+		}
 		for _, fwd := range fwds {
-			if err := m.Forward(
-				tunnel.ConnOpts{
-					Host: fwd.Host,
-					Port: uint(fwd.Port),
-					User: fwd.User,
-				},
-				fwd.Addrs.LocalAddr, fwd.Addrs.RemoteAddr,
-			); err != nil {
-				zap.L().Error("failed to open fwd", zap.Error(err))
+			remote := tunnel.ConnOpts{
+				Host:     fwd.Host,
+				Port:     uint(fwd.Port),
+				User:     fwd.User,
+				Labels:   fwd.TunnelLabels,
+				OwnerUID: fwd.OwnerUid,
+			}
+			ap := tunnel.AddrPairFromProto(fwd.Addrs)
+			if id := ser.Ser(remote.Hash(), ap.Hash()); id != fwd.Id {
+				// stored before ids included the owner
+				if err := m.rekey(fwd, id); err != nil {
+					zap.L().Warn("failed to store fwd under its new id", zap.String("id", fwd.Id), zap.Error(err))
+				}
+			}
+			if err := m.Forward(remote, ap); err != nil {
+				zap.L().Error("failed to open fwd", zap.String(log.TunnelKey, remote.Hash()), zap.String(log.FwdKey, fwd.Id), zap.Error(err))
+				m.markFailed(remote, ap, err)
 			}
 		}
 
@@ -73,6 +111,29 @@ func New() *Manager {
 	return m
 }
 
+// rekey stores the persisted forward fwd under id instead of its current id.
+func (m *Manager) rekey(fwd *ctrlpb.FwdState, id string) error {
+	old := fwd.Id
+	fwd.Id = id
+	if err := m.db.SaveFwd(fwd); err != nil {
+		return err
+	}
+	_, err := m.db.DeleteFwd(old)
+	return err
+}
+
+// newBindPolicy reads the policy for which local addresses forwards may listen on.
+func newBindPolicy() *acl.BindPolicy {
+	allowed, err := acl.ParsePrefixes(viper.GetStringSlice("bind.allow"))
+	if err != nil {
+		zap.L().Error("invalid allowed bind addresses, only loopback binds are allowed", zap.Error(err))
+	}
+	return &acl.BindPolicy{
+		LoopbackOnly: viper.GetBool("bind.loopback-only"),
+		Allowed:      allowed,
+	}
+}
+
 func (m *Manager) Shutdown() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -83,7 +144,8 @@ func (m *Manager) Shutdown() {
 	m.tunnels = make(map[string]*WTunnel)
 }
 
-func (m *Manager) findOrCreate(remote tunnel.ConnOpts) (*WTunnel, error) {
+// findOrCreate returns the tunnel to remote, a new tunnel is not dialed until needed if lazy is set.
+func (m *Manager) findOrCreate(remote tunnel.ConnOpts, lazy bool) (*WTunnel, error) {
 	hash := remote.Hash()
 	m.mu.RLock()
 	if t, found := m.tunnels[hash]; found {
@@ -93,7 +155,17 @@ func (m *Manager) findOrCreate(remote tunnel.ConnOpts) (*WTunnel, error) {
 	m.mu.RUnlock()
 
 	tunCtx, tunCan := context.WithCancel(m.ctx)
-	tun, err := tunnel.New(remote.User, remote.Host, remote.Port, append(remote.Opts, tunnel.WithContext(tunCtx))...)
+	opts := append(remote.Opts,
+		tunnel.WithContext(tunCtx),
+		tunnel.WithReconnectBackoff(viper.GetDuration("reconnect-initial-backoff"), viper.GetDuration("reconnect-max-backoff")),
+		tunnel.WithIdleTimeout(viper.GetDuration("idle-timeout")),
+		tunnel.WithEvents(m.events.Publish),
+		tunnel.WithOwner(remote.OwnerUID),
+	)
+	if lazy {
+		opts = append(opts, tunnel.WithLazy())
+	}
+	tun, err := tunnel.New(remote.User, remote.Host, remote.Port, opts...)
 	if err != nil {
 		tunCan()
 		return nil, err
@@ -110,92 +182,199 @@ func (m *Manager) findOrCreate(remote tunnel.ConnOpts) (*WTunnel, error) {
 	return wtun, nil
 }
 
-func (m *Manager) Forward(remote tunnel.ConnOpts, localAddr string, remoteAddr string) error {
-	tun, err := m.findOrCreate(remote)
+func (m *Manager) Forward(remote tunnel.ConnOpts, ap tunnel.AddressPair) error {
+	if ap.Lazy && ap.Kind == ctrlpb.FwdKind_FWD_REMOTE {
+		return fmt.Errorf("remote forwards can not be lazy")
+	}
+	addrs := ap.Proto()
+	if expired(addrs.ExpiresAt, time.Now()) {
+		return fmt.Errorf("forward has already expired")
+	}
+	if _, err := acl.New(ap.Allow, ap.Deny); err != nil {
+		return err
+	}
+	id := ser.Ser(remote.Hash(), ap.Hash())
+	if err := m.validateName(scope{uid: remote.OwnerUID}, id, ap.Name); err != nil {
+		return err
+	}
+	if err := labels.Validate(ap.Labels); err != nil {
+		return err
+	}
+	if err := labels.Validate(remote.Labels); err != nil {
+		return err
+	}
+	if ap.Kind != ctrlpb.FwdKind_FWD_REMOTE && !utils.IsSocketPath(ap.LocalAddr) {
+		if err := m.bindPolicy.Check(m.ctx, ap.LocalAddr); err != nil {
+			return err
+		}
+	}
+	if err := tunnel.CheckLocalAccess(remote.OwnerUID, ap); err != nil {
+		return err
+	}
+
+	tun, err := m.findOrCreate(remote, ap.Lazy)
 	if err != nil {
 		return err
 	}
 
-	ap := tunnel.AddressPair{LocalAddr: localAddr, RemoteAddr: remoteAddr}
 	if tun.Exists(ap.Hash()) {
 		return fmt.Errorf("connection already exists")
 	}
+	tun.AddLabels(remote.Labels)
 
 	if m.db != nil {
 		if err := m.db.SaveFwd(&ctrlpb.FwdState{
-			Id:    ser.Ser(remote.Hash(), ap.Hash()),
-			Addrs: &ctrlpb.AddrPair{LocalAddr: ap.LocalAddr, RemoteAddr: ap.RemoteAddr},
-			Host:  remote.Host,
-			User:  remote.User,
-			Port:  uint32(remote.Port),
+			Id:           ser.Ser(remote.Hash(), ap.Hash()),
+			Addrs:        &addrs,
+			Host:         remote.Host,
+			User:         remote.User,
+			Port:         uint32(remote.Port),
+			ExpiresAt:    addrs.ExpiresAt,
+			Name:         addrs.Name,
+			Labels:       ap.Labels,
+			TunnelLabels: remote.Labels,
+			OwnerUid:     remote.OwnerUID,
 		}); err != nil {
 			zap.L().Warn("failed to persist fwd", zap.Error(err))
 		}
 	}
 
+	lc := tunnel.NewLifecycle(id)
+	m.track(id, &fwdEntry{remote: remote, ap: ap, lc: lc})
 	go func() {
-		if err := tun.Forward(ap); err != nil {
-			zap.L().Error("error on fwd", zap.String("localAddr", localAddr), zap.String("remoteAddr", remoteAddr), zap.Error(err))
+		if err := tun.Forward(ap, lc); err != nil {
+			// the entry is kept so that the failure shows up in ps
+			zap.L().Error("error on fwd", zap.String(log.TunnelKey, remote.Hash()), zap.String(log.FwdKey, id), zap.String("kind", ap.Kind.String()), zap.String("localAddr", ap.LocalAddr), zap.String("remoteAddr", ap.RemoteAddr), zap.Error(err))
+			return
+		}
+		if m.untrack(id, lc) {
+			// the forward was closed, drop the entries it logged while closing
+			log.Buffers.Forget(id)
 		}
 	}()
 	return nil
 }
 
-func (m *Manager) Ps(_ context.Context, _ *ctrlpb.PsRequest) (*ctrlpb.PsResponse, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// Ps lists the forwards of the caller, or of every user if AllUsers is set.
+func (m *Manager) Ps(ctx context.Context, req *ctrlpb.PsRequest) (*ctrlpb.PsResponse, error) {
+	sc, err := m.scopeOf(ctx, req.AllUsers)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := labels.ParseSelector(req.GetSelector())
+	if err != nil {
+		return &ctrlpb.PsResponse{Errors: []string{err.Error()}}, nil
+	}
 
+	fwds := m.listFwds(sc, sel)
+	for _, fwd := range fwds {
+		redact(fwd)
+	}
+	return &ctrlpb.PsResponse{Fwds: fwds}, nil
+}
+
+// listFwds returns the forwards in sc matching sel, running, failed and persisted ones, with their secrets.
+func (m *Manager) listFwds(sc scope, sel labels.Selector) []*ctrlpb.Fwd {
 	var fwds []*ctrlpb.Fwd
+	running := make(map[string]bool)
+	m.mu.RLock()
 	for _, t := range m.tunnels {
 		parent := t.Proto()
 		for i, a := range parent.AddressPair {
-			fwds = append(fwds, &ctrlpb.Fwd{Id: ser.Ser(parent.Id, i), Addrs: a, Parent: parent})
+			fwd := &ctrlpb.Fwd{Id: ser.Ser(parent.Id, i), Addrs: a, Parent: parent}
+			running[fwd.Id] = true
+			if !sc.owns(parent.OwnerUid) || !matches(sel, fwd) {
+				continue
+			}
+			m.fill(fwd)
+			fwds = append(fwds, fwd)
 		}
 	}
+	m.mu.RUnlock()
 
-	return &ctrlpb.PsResponse{Fwds: fwds}, nil
+	// failed and persisted forwards that are not running
+	for _, fwd := range m.inactiveFwds(sc, running) {
+		if matches(sel, fwd) {
+			fwds = append(fwds, fwd)
+		}
+	}
+	return fwds
 }
 
-func (m *Manager) OpenFwd(_ context.Context, req *ctrlpb.OpenRequest) (*ctrlpb.OpenResponse, error) {
+// OpenFwd opens forwards owned by the caller.
+func (m *Manager) OpenFwd(ctx context.Context, req *ctrlpb.OpenRequest) (*ctrlpb.OpenResponse, error) {
+	return m.openFwds(ctx, req)
+}
+
+// openFwds opens the forwards of req for the caller of ctx, opts are added to the options of new tunnels.
+func (m *Manager) openFwds(ctx context.Context, req *ctrlpb.OpenRequest, opts ...tunnel.ConfigOption) (*ctrlpb.OpenResponse, error) {
 	var opened []string
 	var errors []string = make([]string, 0)
+	owner, err := m.ownerOf(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	for _, tf := range req.Tunnels {
 
 		remote := tunnel.ConnOpts{
-			User: tf.User,
-			Host: tf.Host,
-			Port: uint(tf.Port),
-			Opts: tunnel.WithProtoOpts(tf.Pw, tf.Privkey),
+			User:     tf.User,
+			Host:     tf.Host,
+			Port:     uint(tf.Port),
+			Opts:     append(tunnel.WithProtoOpts(tf.Pw, tf.Privkey), opts...),
+			Labels:   tf.Labels,
+			OwnerUID: owner,
 		}
 
 		for _, fw := range tf.AddressPair {
-			if err := m.Forward(remote, fw.LocalAddr, fw.RemoteAddr); err != nil {
+			ap := tunnel.AddrPairFromProto(fw)
+			if err := m.Forward(remote, ap); err != nil {
 				errors = append(errors, err.Error())
 				zap.L().Warn("failed to forward", zap.String("remoteAddr", fw.RemoteAddr), zap.Error(err))
 				continue
 			}
-			opened = append(opened, ser.Ser(remote.Hash(), tunnel.HashAddrPair(fw.LocalAddr, fw.RemoteAddr)))
+			opened = append(opened, ser.Ser(remote.Hash(), ap.Hash()))
 		}
 	}
 
 	return &ctrlpb.OpenResponse{OpenedIds: opened, Errors: errors}, nil
 }
 
-func (m *Manager) CloseFwd(_ context.Context, req *ctrlpb.CloseRequest) (*ctrlpb.CloseResponse, error) {
+// CloseFwd closes forwards of the caller, or of any user if AllUsers is set.
+func (m *Manager) CloseFwd(ctx context.Context, req *ctrlpb.CloseRequest) (*ctrlpb.CloseResponse, error) {
 	var closed []string
-	var errors []string = make([]string, 0)
 
 	tunConnMap := make(map[string]int)
 
-	for _, id := range req.Ids {
+	sc, err := m.scopeOf(ctx, req.AllUsers)
+	if err != nil {
+		return nil, err
+	}
+	ids, errors := m.resolveAll(sc, req.Ids)
+	if req.Selector != "" {
+		selected, err := m.selectFwds(sc, req.Selector)
+		if err != nil {
+			errors = append(errors, err.Error())
+		} else if len(selected) == 0 {
+			errors = append(errors, fmt.Sprintf("no fwds match selector %q", req.Selector))
+		}
+		for _, id := range selected {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	for _, id := range ids {
 		tunHash, addrHash, err := ser.DeSer(id)
 		if err != nil {
 			errors = append(errors, err.Error())
 			zap.L().Warn("could not deserialize id into tunnel and addr hash", zap.Error(err))
 			continue
 		}
-		if v, ok := m.tunnels[tunHash]; ok {
+		m.mu.RLock()
+		v, ok := m.tunnels[tunHash]
+		m.mu.RUnlock()
+		if ok && v.Exists(addrHash) {
 			if _, ok := tunConnMap[tunHash]; !ok {
 				tunConnMap[tunHash] = v.FwdsCount()
 			}
@@ -218,30 +397,171 @@ func (m *Manager) CloseFwd(_ context.Context, req *ctrlpb.CloseRequest) (*ctrlpb
 				m.mu.Lock()
 				delete(m.tunnels, tunHash)
 				m.mu.Unlock()
-				zap.L().Info("closed empty SSH tunnel")
+				log.Buffers.Forget(tunHash)
+				zap.L().Info("closed empty SSH tunnel", zap.String(log.TunnelKey, tunHash))
 			}
 		} else {
-			errors = append(errors, fmt.Sprintf("could not find tunnel by { \"id\": \"%s\"}", id))
+			// failed or persisted but not running, only ids that were actually removed are closed
+			var removed bool
+			if e, failed := m.entry(id); failed && sc.owns(e.remote.OwnerUID) {
+				removed = m.untrack(id, e.lc)
+			}
+			if m.db != nil {
+				deleted, err := m.db.DeleteFwd(id)
+				if err != nil {
+					errors = append(errors, err.Error())
+					continue
+				}
+				removed = removed || deleted
+			}
+			if !removed {
+				errors = append(errors, fmt.Sprintf("could not find tunnel by { \"id\": \"%s\"}", id))
+				continue
+			}
+			closed = append(closed, id)
+			log.Buffers.Forget(id)
+			if ok && v.FwdsCount() == 0 {
+				v.Close()
+				m.mu.Lock()
+				delete(m.tunnels, tunHash)
+				m.mu.Unlock()
+				log.Buffers.Forget(tunHash)
+			}
 		}
 	}
 	return &ctrlpb.CloseResponse{ClosedIds: closed, Errors: errors}, nil
 }
 
-func (m *Manager) CloseAllFwds(context.Context, *ctrlpb.CloseAllRequest) (*ctrlpb.CloseAllResponse, error) {
+// CloseAllFwds closes all tunnels of the caller, or of every user if AllUsers is set.
+func (m *Manager) CloseAllFwds(ctx context.Context, req *ctrlpb.CloseAllRequest) (*ctrlpb.CloseAllResponse, error) {
+	sc, err := m.scopeOf(ctx, req.AllUsers)
+	if err != nil {
+		return nil, err
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if len(m.tunnels) > 0 {
-		for id, tun := range m.tunnels {
-			tun.Close()
-			zap.L().Info("closed tunnel", zap.String("id", id))
+	var closed int
+	for id, tun := range m.tunnels {
+		if !sc.owns(tun.OwnerUID()) {
+			continue
+		}
+		tun.Close()
+		delete(m.tunnels, id)
+		log.Buffers.Forget(id)
+		closed++
+		zap.L().Info("closed tunnel", zap.String("id", id))
+	}
+	closed += m.dropFailed(sc)
+	if m.db != nil {
+		// also counts the forwards that are persisted but not running
+		if fwds, err := m.db.LoadAllFwds(); err == nil {
+			for _, fwd := range fwds {
+				if sc.owns(fwd.OwnerUid) {
+					closed++
+				}
+			}
+		}
+		if sc.all {
+			err = m.db.NukeFwds()
+		} else {
+			err = m.db.NukeOwnerFwds(sc.uid)
+		}
+		if err != nil {
+			zap.L().Error("failed to nuke fwds bucket", zap.Error(err))
+		}
+	}
+	if closed == 0 {
+		return &ctrlpb.CloseAllResponse{Ok: false, Error: "No open tunnels"}, nil
+	}
+	return &ctrlpb.CloseAllResponse{Ok: true, Error: ""}, nil
+}
+
+func (m *Manager) Stats(ctx context.Context, req *ctrlpb.StatsRequest) (*ctrlpb.StatsResponse, error) {
+	var stats []*ctrlpb.FwdStats
+	sc, err := m.scopeOf(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	ids, errors := m.resolveAll(sc, req.Ids)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(req.Ids) == 0 {
+		for _, t := range m.tunnels {
+			if !sc.owns(t.OwnerUID()) {
+				continue
+			}
+			s, _ := t.Stats()
+			stats = append(stats, s...)
+		}
+		return &ctrlpb.StatsResponse{Stats: stats, Errors: errors}, nil
+	}
+
+	for _, id := range ids {
+		tunHash, addrHash, err := ser.DeSer(id)
+		if err != nil {
+			errors = append(errors, err.Error())
+			continue
+		}
+		t, ok := m.tunnels[tunHash]
+		if !ok {
+			errors = append(errors, fmt.Sprintf("fwd %s is not running", id))
+			continue
+		}
+		s, notFound := t.Stats(addrHash)
+		stats = append(stats, s...)
+		for _, nf := range notFound {
+			errors = append(errors, fmt.Sprintf("fwd with id %s not found", nf))
+		}
+	}
+
+	return &ctrlpb.StatsResponse{Stats: stats, Errors: errors}, nil
+}
+
+func (m *Manager) Inspect(ctx context.Context, req *ctrlpb.InspectRequest) (*ctrlpb.InspectResponse, error) {
+	var fwds []*ctrlpb.FwdDetails
+	sc, err := m.scopeOf(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	ids, errors := m.resolveAll(sc, req.Ids)
+
+	for _, id := range ids {
+		tunHash, addrHash, err := ser.DeSer(id)
+		if err != nil {
+			errors = append(errors, err.Error())
+			continue
 		}
-		m.tunnels = make(map[string]*WTunnel)
+
+		m.mu.RLock()
+		t, ok := m.tunnels[tunHash]
+		m.mu.RUnlock()
+		if ok {
+			parent := t.Proto()
+			if a, ok := parent.AddressPair[addrHash]; ok {
+				// the siblings are not part of the details of this fwd
+				parent.AddressPair = nil
+				details := &ctrlpb.FwdDetails{Fwd: redact(&ctrlpb.Fwd{Id: id, Addrs: a, Parent: parent}), Running: true}
+				m.fill(details.Fwd)
+				if s, _ := t.Stats(addrHash); len(s) > 0 {
+					details.Stats = s[0]
+				}
+				fwds = append(fwds, details)
+				continue
+			}
+		}
+
 		if m.db != nil {
-			if err := m.db.NukeFwds(); err != nil {
-				zap.L().Error("failed to nuke fwds bucket", zap.Error(err))
+			if st, err := m.db.LoadFwd(id); err == nil {
+				fwd := redact(storedFwd(st))
+				m.fill(fwd)
+				fwds = append(fwds, &ctrlpb.FwdDetails{Fwd: fwd})
+				continue
 			}
 		}
-		return &ctrlpb.CloseAllResponse{Ok: true, Error: ""}, nil
+		errors = append(errors, fmt.Sprintf("fwd with id %s not found", id))
 	}
-	return &ctrlpb.CloseAllResponse{Ok: false, Error: "No open tunnels"}, nil
+
+	return &ctrlpb.InspectResponse{Fwds: fwds, Errors: errors}, nil
 }
@@ -0,0 +1,47 @@
+package manager
+
+import (
+	"context"
+
+	"github.com/Phillezi/tunman/pkg/caller"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// scope is the set of forwards a request may see and act on, those owned by uid or all of them.
+type scope struct {
+	uid uint32
+	all bool
+}
+
+func (s scope) owns(uid uint32) bool {
+	return s.all || s.uid == uid
+}
+
+// scopeOf returns the scope of the caller of ctx, allUsers widens it to the forwards of every user
+// which only admins may do. Requests without a caller are made by the daemon itself and are not limited to an owner.
+func (m *Manager) scopeOf(ctx context.Context, allUsers bool) (scope, error) {
+	c, ok := caller.FromContext(ctx)
+	if !ok {
+		return scope{uid: m.uid, all: true}, nil
+	}
+	if allUsers && !c.Admin {
+		return scope{}, status.Errorf(codes.PermissionDenied, "only admins may act on the forwards of all users, uid %d is not an admin", c.UID)
+	}
+	return scope{uid: c.UID, all: allUsers}, nil
+}
+
+// ownerOf returns the user that the forwards opened by the caller of ctx belong to,
+// the user running the daemon if there is no caller.
+// Tunnels are dialed with the ssh config, keys, agent and known_hosts of the user running the daemon,
+// so other users may only open forwards if they are admins.
+func (m *Manager) ownerOf(ctx context.Context) (uint32, error) {
+	c, ok := caller.FromContext(ctx)
+	if !ok {
+		return m.uid, nil
+	}
+	if c.UID != m.uid && !c.Admin {
+		return 0, status.Errorf(codes.PermissionDenied, "tunnels are opened with the ssh setup of uid %d, uid %d is not an admin and may not use it", m.uid, c.UID)
+	}
+	return c.UID, nil
+}
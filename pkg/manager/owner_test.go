@@ -0,0 +1,66 @@
+package manager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Phillezi/tunman/pkg/caller"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestScopeOf(t *testing.T) {
+	m := &Manager{uid: 1000}
+	user := caller.NewContext(context.Background(), caller.Caller{UID: 1001})
+	admin := caller.NewContext(context.Background(), caller.Caller{UID: 1002, Admin: true})
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		allUsers bool
+		want     scope
+		code     codes.Code
+	}{
+		{name: "daemon", ctx: context.Background(), want: scope{uid: 1000, all: true}},
+		{name: "user", ctx: user, want: scope{uid: 1001}},
+		{name: "user of all users", ctx: user, allUsers: true, code: codes.PermissionDenied},
+		{name: "admin", ctx: admin, want: scope{uid: 1002}},
+		{name: "admin of all users", ctx: admin, allUsers: true, want: scope{uid: 1002, all: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.scopeOf(tt.ctx, tt.allUsers)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("got %v (%v), want %v", code, err, tt.code)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnerOf(t *testing.T) {
+	m := &Manager{uid: 1000}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want uint32
+		code codes.Code
+	}{
+		{name: "daemon", ctx: context.Background(), want: 1000},
+		{name: "user running the daemon", ctx: caller.NewContext(context.Background(), caller.Caller{UID: 1000}), want: 1000},
+		{name: "admin", ctx: caller.NewContext(context.Background(), caller.Caller{UID: 1002, Admin: true}), want: 1002},
+		{name: "other user", ctx: caller.NewContext(context.Background(), caller.Caller{UID: 1001}), code: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.ownerOf(tt.ctx)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("got %v (%v), want %v", code, err, tt.code)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
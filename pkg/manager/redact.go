@@ -0,0 +1,34 @@
+package manager
+
+import (
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// redact removes the secrets of fwd and its siblings before fwd is sent to a client,
+// the SOCKS passwords are only kept by the daemon and in its store.
+func redact(fwd *ctrlpb.Fwd) *ctrlpb.Fwd {
+	fwd.Addrs = redactAddrs(fwd.Addrs)
+	if fwd.Parent != nil {
+		redactTunnel(fwd.Parent)
+	}
+	return fwd
+}
+
+// redactTunnel removes the secrets of the forwards of t.
+func redactTunnel(t *ctrlpb.Tunnel) *ctrlpb.Tunnel {
+	for id, a := range t.AddressPair {
+		t.AddressPair[id] = redactAddrs(a)
+	}
+	return t
+}
+
+// redactAddrs returns a without its SOCKS password, a is copied since its options may be shared with the running forward.
+func redactAddrs(a *ctrlpb.AddrPair) *ctrlpb.AddrPair {
+	if a.GetSocks().GetPassword() == "" {
+		return a
+	}
+	a = proto.Clone(a).(*ctrlpb.AddrPair)
+	a.Socks.Password = ""
+	return a
+}
@@ -0,0 +1,162 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Phillezi/tunman/pkg/labels"
+	"github.com/Phillezi/tunman/pkg/ser"
+)
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// fwdRef identifies a running or persisted forward,
+// labels are the labels of the forward merged over the labels of its tunnel.
+type fwdRef struct {
+	id     string
+	name   string
+	labels map[string]string
+}
+
+// validateName checks that name can be used for the forward with the given id, names are unique per owner.
+func (m *Manager) validateName(sc scope, id, name string) error {
+	if name == "" {
+		return nil
+	}
+	if !namePattern.MatchString(name) || name == "all" {
+		return fmt.Errorf("invalid name %q, names may only contain letters, digits, '_' and '-'", name)
+	}
+	for _, ref := range m.knownFwds(sc) {
+		if ref.name == name && ref.id != id {
+			return fmt.Errorf("name %q is already used by fwd %s", name, ref.id)
+		}
+	}
+	return nil
+}
+
+// knownFwds returns the running forwards in sc, the failed ones and the persisted ones that are not running.
+func (m *Manager) knownFwds(sc scope) []fwdRef {
+	var refs []fwdRef
+	seen := make(map[string]bool)
+
+	m.mu.RLock()
+	for tunHash, t := range m.tunnels {
+		if !sc.owns(t.OwnerUID()) {
+			continue
+		}
+		parent := t.Proto()
+		for id, a := range parent.AddressPair {
+			id = ser.Ser(tunHash, id)
+			seen[id] = true
+			refs = append(refs, fwdRef{id: id, name: a.GetName(), labels: labels.Merge(parent.Labels, a.Labels)})
+		}
+	}
+	m.mu.RUnlock()
+
+	m.entriesMu.Lock()
+	for id, e := range m.entries {
+		if !seen[id] && sc.owns(e.remote.OwnerUID) {
+			seen[id] = true
+			refs = append(refs, fwdRef{id: id, name: e.ap.Name, labels: labels.Merge(e.remote.Labels, e.ap.Labels)})
+		}
+	}
+	m.entriesMu.Unlock()
+
+	if m.db != nil {
+		fwds, _ := m.db.LoadAllFwds()
+		for _, fwd := range fwds {
+			if !seen[fwd.Id] && sc.owns(fwd.OwnerUid) {
+				refs = append(refs, fwdRef{id: fwd.Id, name: fwd.Name, labels: labels.Merge(fwd.TunnelLabels, fwd.Labels)})
+			}
+		}
+	}
+	return refs
+}
+
+// resolve returns the id of the forward in sc that ref refers to, ref is a full id, a name
+// or a prefix of either the full id or the forward part of it that only matches a single forward.
+func (m *Manager) resolve(sc scope, ref string) (string, error) {
+	if ref == "" {
+		return "", fmt.Errorf("empty fwd id")
+	}
+	if tunHash, fwdID, err := ser.DeSer(ref); err == nil {
+		m.mu.RLock()
+		t, ok := m.tunnels[tunHash]
+		m.mu.RUnlock()
+		if ok && sc.owns(t.OwnerUID()) && t.Exists(fwdID) {
+			return ref, nil
+		}
+	}
+
+	refs := m.knownFwds(sc)
+	var named []string
+	for _, r := range refs {
+		if r.id == ref {
+			return r.id, nil
+		}
+		if r.name == ref {
+			named = append(named, r.id)
+		}
+	}
+	switch len(named) {
+	case 0:
+	case 1:
+		return named[0], nil
+	default:
+		// names are only unique per owner
+		slices.Sort(named)
+		return "", fmt.Errorf("name %q is ambiguous, it is used by %d fwds of different users: %s", ref, len(named), strings.Join(named, ", "))
+	}
+
+	var matches []string
+	for _, r := range refs {
+		_, fwdID, _ := ser.DeSer(r.id)
+		if strings.HasPrefix(r.id, ref) || strings.HasPrefix(fwdID, ref) {
+			matches = append(matches, r.id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no fwd found by id or name %q", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		slices.Sort(matches)
+		return "", fmt.Errorf("id prefix %q is ambiguous, it matches %d fwds: %s", ref, len(matches), strings.Join(matches, ", "))
+	}
+}
+
+// selectFwds returns the ids of the forwards in sc whose labels match selector.
+func (m *Manager) selectFwds(sc scope, selector string) ([]string, error) {
+	sel, err := labels.ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, r := range m.knownFwds(sc) {
+		if sel.Matches(r.labels) {
+			ids = append(ids, r.id)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// resolveAll resolves refs into ids, refs that can not be resolved are returned as errors.
+func (m *Manager) resolveAll(sc scope, refs []string) ([]string, []string) {
+	var ids []string
+	var errors []string
+	for _, ref := range refs {
+		id, err := m.resolve(sc, ref)
+		if err != nil {
+			errors = append(errors, err.Error())
+			continue
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, errors
+}
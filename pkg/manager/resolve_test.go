@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Phillezi/tunman/pkg/repo"
+	"github.com/Phillezi/tunman/pkg/ser"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+)
+
+func TestResolveNames(t *testing.T) {
+	db, err := repo.OpenDB(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	m := &Manager{uid: 1000, db: db, tunnels: make(map[string]*WTunnel), entries: make(map[string]*fwdEntry)}
+
+	alice, bob := ser.Ser("aaaa", "1111"), ser.Ser("bbbb", "2222")
+	for id, owner := range map[string]uint32{alice: 1001, bob: 1002} {
+		if err := db.SaveFwd(&ctrlpb.FwdState{Id: id, OwnerUid: owner, Name: "db", Addrs: &ctrlpb.AddrPair{Name: "db"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		sc      scope
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{name: "own name", sc: scope{uid: 1001}, ref: "db", want: alice},
+		{name: "other own name", sc: scope{uid: 1002}, ref: "db", want: bob},
+		{name: "name of another user", sc: scope{uid: 1003}, ref: "db", wantErr: "no fwd found"},
+		{name: "duplicate name of all users", sc: scope{uid: 1000, all: true}, ref: "db", wantErr: "ambiguous"},
+		{name: "id of all users", sc: scope{uid: 1000, all: true}, ref: bob, want: bob},
+		{name: "id prefix", sc: scope{uid: 1000, all: true}, ref: "aaaa", want: alice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.resolve(tt.sc, tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %q, %v, want error containing %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package manager
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/Phillezi/tunman/log"
+	"github.com/Phillezi/tunman/pkg/labels"
+	"github.com/Phillezi/tunman/pkg/ser"
+	"github.com/Phillezi/tunman/pkg/tunnel"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"go.uber.org/zap"
+)
+
+// fwdEntry is a forward that has been opened by the manager,
+// it is kept after the forward has failed so that the failure can be shown.
+type fwdEntry struct {
+	remote tunnel.ConnOpts
+	ap     tunnel.AddressPair
+	lc     *tunnel.Lifecycle
+}
+
+func (m *Manager) track(id string, e *fwdEntry) {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+	m.entries[id] = e
+}
+
+// untrack removes the entry of id if it still belongs to lc, the forward may have been opened again.
+// It reports if the entry was removed, a nil lc removes any entry.
+func (m *Manager) untrack(id string, lc *tunnel.Lifecycle) bool {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+	if e, ok := m.entries[id]; ok && (lc == nil || e.lc == lc) {
+		delete(m.entries, id)
+		return true
+	}
+	return false
+}
+
+// dropFailed removes the entries of the forwards in sc that failed and returns how many there were.
+func (m *Manager) dropFailed(sc scope) int {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+	var dropped int
+	for id, e := range m.entries {
+		if sc.owns(e.remote.OwnerUID) && e.lc.Status() == ctrlpb.FwdStatus_FWD_FAILED {
+			delete(m.entries, id)
+			log.Buffers.Forget(id)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+func (m *Manager) entry(id string) (*fwdEntry, bool) {
+	m.entriesMu.Lock()
+	defer m.entriesMu.Unlock()
+	e, ok := m.entries[id]
+	return e, ok
+}
+
+// markFailed records a forward that could not be opened.
+func (m *Manager) markFailed(remote tunnel.ConnOpts, ap tunnel.AddressPair, err error) {
+	id := ser.Ser(remote.Hash(), ap.Hash())
+	lc := tunnel.NewLifecycle(id)
+	lc.Set(ctrlpb.FwdStatus_FWD_FAILED, err)
+	m.track(id, &fwdEntry{remote: remote, ap: ap, lc: lc})
+}
+
+// fill sets the status of fwd from its entry, forwards without one have not been opened yet.
+func (m *Manager) fill(fwd *ctrlpb.Fwd) {
+	if e, ok := m.entry(fwd.Id); ok {
+		e.lc.Fill(fwd)
+		return
+	}
+	fwd.Status = ctrlpb.FwdStatus_FWD_PENDING
+}
+
+// inactiveFwds returns the forwards in sc that are known but not running, failed ones and persisted ones
+// that have not been opened, running holds the ids of the running forwards.
+func (m *Manager) inactiveFwds(sc scope, running map[string]bool) []*ctrlpb.Fwd {
+	var fwds []*ctrlpb.Fwd
+
+	m.entriesMu.Lock()
+	entries := maps.Clone(m.entries)
+	m.entriesMu.Unlock()
+	for _, id := range slices.Sorted(maps.Keys(entries)) {
+		e := entries[id]
+		if running[id] || !sc.owns(e.remote.OwnerUID) {
+			continue
+		}
+		addrs := e.ap.Proto()
+		fwd := &ctrlpb.Fwd{
+			Id:    id,
+			Addrs: &addrs,
+			Parent: &ctrlpb.Tunnel{
+				Id:       e.remote.Hash(),
+				User:     e.remote.User,
+				Host:     e.remote.Host,
+				Port:     uint32(e.remote.Port),
+				Labels:   e.remote.Labels,
+				OwnerUid: e.remote.OwnerUID,
+			},
+		}
+		e.lc.Fill(fwd)
+		fwds = append(fwds, fwd)
+	}
+
+	if m.db == nil {
+		return fwds
+	}
+	stored, err := m.db.LoadAllFwds()
+	if err != nil {
+		zap.L().Warn("failed to load persisted fwds", zap.Error(err))
+	}
+	for _, st := range stored {
+		if _, ok := entries[st.Id]; ok || running[st.Id] || !sc.owns(st.OwnerUid) {
+			continue
+		}
+		fwds = append(fwds, storedFwd(st))
+	}
+	return fwds
+}
+
+// storedFwd returns a persisted forward that is not running.
+func storedFwd(st *ctrlpb.FwdState) *ctrlpb.Fwd {
+	tunHash, _, _ := ser.DeSer(st.Id)
+	return &ctrlpb.Fwd{
+		Id:     st.Id,
+		Addrs:  st.Addrs,
+		Parent: &ctrlpb.Tunnel{Id: tunHash, User: st.User, Host: st.Host, Port: st.Port, Labels: st.TunnelLabels, OwnerUid: st.OwnerUid},
+		Status: ctrlpb.FwdStatus_FWD_PENDING,
+	}
+}
+
+// matches reports if the labels of fwd merged over the labels of its tunnel match sel.
+func matches(sel labels.Selector, fwd *ctrlpb.Fwd) bool {
+	return sel.Matches(labels.Merge(fwd.Parent.GetLabels(), fwd.Addrs.GetLabels()))
+}
@@ -0,0 +1,58 @@
+package manager
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Phillezi/tunman/pkg/events"
+	"github.com/Phillezi/tunman/pkg/ser"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Watch streams the events of the tunnels and forwards of the caller until the client goes away.
+// Events of a whole tunnel are sent to watchers of any of its forwards.
+func (m *Manager) Watch(req *ctrlpb.WatchRequest, stream grpc.ServerStreamingServer[ctrlpb.Event]) error {
+	sc, err := m.scopeOf(stream.Context(), false)
+	if err != nil {
+		return err
+	}
+	ids, errors := m.resolveAll(sc, req.Ids)
+	if len(errors) > 0 {
+		return status.Error(codes.NotFound, strings.Join(errors, ", "))
+	}
+	tunnels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if tunHash, _, err := ser.DeSer(id); err == nil {
+			tunnels = append(tunnels, tunHash)
+		}
+	}
+
+	ofTypes := events.OfTypes(req.Types...)
+	evs, unsubscribe := m.events.Subscribe(func(ev *ctrlpb.Event) bool {
+		if !sc.owns(ev.OwnerUid) || !ofTypes(ev) {
+			return false
+		}
+		if len(ids) == 0 {
+			return true
+		}
+		if ev.FwdId == "" {
+			return slices.Contains(tunnels, ev.TunnelId)
+		}
+		return slices.Contains(ids, ev.FwdId)
+	})
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case ev := <-evs:
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
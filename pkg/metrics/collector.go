@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	tunnelUpDesc = prometheus.NewDesc(namespace+"_tunnel_up",
+		"Whether the ssh connection of a tunnel is up.", []string{"tunnel", "host"}, nil)
+	reconnectsDesc = prometheus.NewDesc(namespace+"_tunnel_reconnects_total",
+		"Attempts to redial the lost ssh connection of a tunnel.", []string{"tunnel", "host"}, nil)
+	stateDesc = prometheus.NewDesc(namespace+"_forward_state",
+		"The state of a forward, 1 for the current state.", []string{"host", "forward", "id", "state"}, nil)
+	bytesDesc = prometheus.NewDesc(namespace+"_forward_bytes_total",
+		"Bytes transferred by a forward, in is sent by its clients and out is sent back to them.", []string{"host", "forward", "id", "direction"}, nil)
+	activeDesc = prometheus.NewDesc(namespace+"_forward_active_connections",
+		"Open connections of a forward.", []string{"host", "forward", "id"}, nil)
+	connsDesc = prometheus.NewDesc(namespace+"_forward_connections_total",
+		"Connections accepted by a forward.", []string{"host", "forward", "id"}, nil)
+	dialFailuresDesc = prometheus.NewDesc(namespace+"_forward_dial_failures_total",
+		"Failed dials to the target of a forward.", []string{"host", "forward", "id"}, nil)
+	rejectedDesc = prometheus.NewDesc(namespace+"_forward_rejected_connections_total",
+		"Connections refused by the acl of a forward.", []string{"host", "forward", "id"}, nil)
+)
+
+// collector collects the state of the tunnels and forwards from the manager on every scrape.
+type collector struct {
+	src Source
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{tunnelUpDesc, reconnectsDesc, stateDesc, bytesDesc, activeDesc, connsDesc, dialFailuresDesc, rejectedDesc} {
+		ch <- d
+	}
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	ps, err := c.src.Ps(ctx, &ctrlpb.PsRequest{})
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(stateDesc, err)
+		return
+	}
+	stats, err := c.src.Stats(ctx, &ctrlpb.StatsRequest{})
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(bytesDesc, err)
+		return
+	}
+
+	fwdLabels := make(map[string][]string, len(ps.Fwds))
+	tunnels := make(map[string]*ctrlpb.Tunnel)
+	for _, fwd := range ps.Fwds {
+		host := fwd.Parent.GetHost()
+		name := ForwardLabel(fwd.Addrs.GetName(), fwd.Id)
+		fwdLabels[fwd.Id] = []string{host, name, fwd.Id}
+		for _, s := range ctrlpb.FwdStatus_name {
+			value := 0.0
+			if s == fwd.Status.String() {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(stateDesc, prometheus.GaugeValue, value, host, name, fwd.Id, stateName(s))
+		}
+		// forwards that are not running have a tunnel without a connection state
+		if fwd.Status != ctrlpb.FwdStatus_FWD_PENDING && fwd.Status != ctrlpb.FwdStatus_FWD_FAILED {
+			tunnels[fwd.Parent.GetId()] = fwd.Parent
+		}
+	}
+
+	for id, t := range tunnels {
+		up := 0.0
+		if t.Connected {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(tunnelUpDesc, prometheus.GaugeValue, up, id, t.Host)
+		ch <- prometheus.MustNewConstMetric(reconnectsDesc, prometheus.CounterValue, float64(t.ReconnectAttempts), id, t.Host)
+	}
+
+	for _, s := range stats.Stats {
+		labels, ok := fwdLabels[s.Id]
+		if !ok {
+			// opened after ps was collected
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(bytesDesc, prometheus.CounterValue, float64(s.BytesIn), append(labels, "in")...)
+		ch <- prometheus.MustNewConstMetric(bytesDesc, prometheus.CounterValue, float64(s.BytesOut), append(labels, "out")...)
+		ch <- prometheus.MustNewConstMetric(activeDesc, prometheus.GaugeValue, float64(s.ActiveConns), labels...)
+		ch <- prometheus.MustNewConstMetric(connsDesc, prometheus.CounterValue, float64(s.TotalConns), labels...)
+		ch <- prometheus.MustNewConstMetric(dialFailuresDesc, prometheus.CounterValue, float64(s.DialFailures), labels...)
+		ch <- prometheus.MustNewConstMetric(rejectedDesc, prometheus.CounterValue, float64(s.Rejected), labels...)
+	}
+}
+
+// stateName is the value of the state label, e.g. listening for FWD_LISTENING.
+func stateName(status string) string {
+	return strings.ToLower(strings.TrimPrefix(status, "FWD_"))
+}
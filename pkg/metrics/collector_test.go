@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeSource struct {
+	fwds  []*ctrlpb.Fwd
+	stats []*ctrlpb.FwdStats
+}
+
+func (s fakeSource) Ps(context.Context, *ctrlpb.PsRequest) (*ctrlpb.PsResponse, error) {
+	return &ctrlpb.PsResponse{Fwds: s.fwds}, nil
+}
+
+func (s fakeSource) Stats(context.Context, *ctrlpb.StatsRequest) (*ctrlpb.StatsResponse, error) {
+	return &ctrlpb.StatsResponse{Stats: s.stats}, nil
+}
+
+func TestCollectSameNameOfTwoOwners(t *testing.T) {
+	src := fakeSource{}
+	for _, owner := range []struct {
+		tunnel string
+		uid    uint32
+	}{{"aaaa", 1001}, {"bbbb", 1002}} {
+		id := owner.tunnel + ".1111"
+		src.fwds = append(src.fwds, &ctrlpb.Fwd{
+			Id:     id,
+			Addrs:  &ctrlpb.AddrPair{Name: "db"},
+			Parent: &ctrlpb.Tunnel{Id: owner.tunnel, Host: "prod", OwnerUid: owner.uid, Connected: true},
+			Status: ctrlpb.FwdStatus_FWD_LISTENING,
+		})
+		src.stats = append(src.stats, &ctrlpb.FwdStats{Id: id, BytesIn: 1})
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(&collector{src: src})
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() == namespace+"_forward_bytes_total" && len(f.GetMetric()) != 4 {
+			t.Errorf("got %d byte series, want 4", len(f.GetMetric()))
+		}
+	}
+}
+
+func TestForgetForward(t *testing.T) {
+	DialObserver("prod", "db", "aaaa.1111")(1)
+	DialObserver("prod", "db", "bbbb.1111")(1)
+	ForgetForward("prod", "db", "aaaa.1111")
+	defer ForgetForward("prod", "db", "bbbb.1111")
+
+	if n := testutil.CollectAndCount(dialDuration); n != 1 {
+		t.Errorf("got %d dial histograms after forgetting one of two, want 1", n)
+	}
+}
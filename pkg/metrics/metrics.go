@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/Phillezi/tunman/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "tunman"
+
+// Registry holds the metrics of the daemon.
+var Registry = prometheus.NewRegistry()
+
+var (
+	dialDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "forward_dial_duration_seconds",
+		Help:      "Time taken to dial the target of a forward for an accepted connection.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"host", "forward", "id"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Time taken to handle control plane RPCs.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+)
+
+func init() {
+	Registry.MustRegister(
+		dialDuration,
+		rpcDuration,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ForwardLabel is the value of the forward label, the name of the forward or its id if it has none.
+// Names are only unique per owner, so the forward metrics are also labeled by the id of the forward.
+func ForwardLabel(name, id string) string {
+	return utils.Or(name, id)
+}
+
+// DialObserver returns a func that records the dial latency of a forward.
+func DialObserver(host, forward, id string) func(time.Duration) {
+	observer := dialDuration.WithLabelValues(host, forward, id)
+	return func(d time.Duration) { observer.Observe(d.Seconds()) }
+}
+
+// ForgetForward drops the dial latencies of a closed forward.
+func ForgetForward(host, forward, id string) {
+	dialDuration.DeleteLabelValues(host, forward, id)
+}
+
+// UnaryInterceptor records the latency of unary RPCs.
+func UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+	rpcDuration.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// Source provides the state of the tunnels and forwards when metrics are collected.
+type Source interface {
+	Ps(context.Context, *ctrlpb.PsRequest) (*ctrlpb.PsResponse, error)
+	Stats(context.Context, *ctrlpb.StatsRequest) (*ctrlpb.StatsResponse, error)
+}
+
+// ListenAndServe serves the metrics of the daemon and the state of src in the Prometheus text format on addr.
+func ListenAndServe(addr string, src Source) error {
+	if err := Registry.Register(&collector{src: src}); err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+	return http.ListenAndServe(addr, mux)
+}
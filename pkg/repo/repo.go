@@ -3,6 +3,8 @@ package repo
 import (
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 
 	ctrlpb "github.com/Phillezi/tunman/proto"
 	"go.etcd.io/bbolt"
@@ -16,7 +18,10 @@ var (
 )
 
 const (
+	// bucketFwds holds the forwards stored before they had owners, they are moved to the user running the daemon
 	bucketFwds = "fwds"
+	// bucketOwners holds a bucket of forwards per owner, keyed by the uid of the owner
+	bucketOwners = "owners"
 )
 
 type Repo struct {
@@ -31,10 +36,10 @@ func OpenDB(path string) (*Repo, error) {
 	}
 
 	err = db.Update(func(tx *bbolt.Tx) error {
-		if _, err := tx.CreateBucketIfNotExists([]byte(bucketFwds)); err != nil {
-			return fmt.Errorf("create bucket %s: %w", bucketFwds, err)
+		if _, err := tx.CreateBucketIfNotExists([]byte(bucketOwners)); err != nil {
+			return fmt.Errorf("create bucket %s: %w", bucketOwners, err)
 		}
-		return nil
+		return migrateLegacy(tx, uint32(os.Getuid()))
 	})
 	if err != nil {
 		db.Close()
@@ -44,15 +49,68 @@ func OpenDB(path string) (*Repo, error) {
 	return &Repo{db: db}, nil
 }
 
+// migrateLegacy moves the forwards stored before they had owners to the bucket of owner.
+func migrateLegacy(tx *bbolt.Tx, owner uint32) error {
+	legacy := tx.Bucket([]byte(bucketFwds))
+	if legacy == nil {
+		return nil
+	}
+	b, err := ownerBucket(tx, owner)
+	if err != nil {
+		return err
+	}
+	err = legacy.ForEach(func(k, v []byte) error {
+		var f ctrlpb.FwdState
+		if err := proto.Unmarshal(v, &f); err != nil {
+			return err
+		}
+		f.OwnerUid = owner
+		data, err := proto.Marshal(&f)
+		if err != nil {
+			return err
+		}
+		return b.Put(k, data)
+	})
+	if err != nil {
+		return fmt.Errorf("migrate bucket %s: %w", bucketFwds, err)
+	}
+	zap.L().Info("moved stored fwds to their owner", zap.Int("count", legacy.Stats().KeyN), zap.Uint32("owner", owner))
+	return tx.DeleteBucket([]byte(bucketFwds))
+}
+
+// ownerBucket returns the bucket of the forwards of owner, creating it if needed.
+func ownerBucket(tx *bbolt.Tx, owner uint32) (*bbolt.Bucket, error) {
+	return tx.Bucket([]byte(bucketOwners)).CreateBucketIfNotExists(ownerKey(owner))
+}
+
+func ownerKey(owner uint32) []byte {
+	return []byte(strconv.FormatUint(uint64(owner), 10))
+}
+
+// forEachOwner calls fn with the bucket of every owner.
+func forEachOwner(tx *bbolt.Tx, fn func(owner uint32, b *bbolt.Bucket) error) error {
+	owners := tx.Bucket([]byte(bucketOwners))
+	return owners.ForEachBucket(func(k []byte) error {
+		owner, err := strconv.ParseUint(string(k), 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid owner %q: %w", k, err)
+		}
+		return fn(uint32(owner), owners.Bucket(k))
+	})
+}
+
 func (r *Repo) Close() error {
 	defer zap.L().Info("db closed")
 	return r.db.Close()
 }
 
-// SaveFwd stores or updates a fwd.
+// SaveFwd stores or updates a fwd in the bucket of its owner.
 func (r *Repo) SaveFwd(f *ctrlpb.FwdState) error {
 	return r.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket([]byte(bucketFwds))
+		b, err := ownerBucket(tx, f.OwnerUid)
+		if err != nil {
+			return err
+		}
 		data, err := proto.Marshal(f)
 		if err != nil {
 			return err
@@ -61,46 +119,87 @@ func (r *Repo) SaveFwd(f *ctrlpb.FwdState) error {
 	})
 }
 
-// LoadFwd loads a fwd by hash.
+// LoadFwd loads a fwd by hash, of any owner.
 func (r *Repo) LoadFwd(id string) (*ctrlpb.FwdState, error) {
-	var f ctrlpb.FwdState
+	var f *ctrlpb.FwdState
 	err := r.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket([]byte(bucketFwds))
-		data := b.Get([]byte(id))
-		if data == nil {
-			return ErrNotFound
-		}
-		return proto.Unmarshal(data, &f)
+		return forEachOwner(tx, func(owner uint32, b *bbolt.Bucket) error {
+			data := b.Get([]byte(id))
+			if data == nil || f != nil {
+				return nil
+			}
+			f = &ctrlpb.FwdState{}
+			if err := proto.Unmarshal(data, f); err != nil {
+				return err
+			}
+			f.OwnerUid = owner
+			return nil
+		})
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &f, nil
+	if f == nil {
+		return nil, ErrNotFound
+	}
+	return f, nil
 }
 
-// LoadAllFwds loads all fwds from the DB.
+// LoadAllFwds loads the fwds of all owners from the DB.
 func (r *Repo) LoadAllFwds() ([]*ctrlpb.FwdState, error) {
 	var fwds []*ctrlpb.FwdState
 	err := r.db.View(func(tx *bbolt.Tx) error {
-		b := tx.Bucket([]byte(bucketFwds))
-		return b.ForEach(func(_, v []byte) error {
-			var f ctrlpb.FwdState
-			if err := proto.Unmarshal(v, &f); err != nil {
-				return err
-			}
-			fwds = append(fwds, &f)
-			return nil
+		return forEachOwner(tx, func(owner uint32, b *bbolt.Bucket) error {
+			owned, err := loadFwds(b, owner)
+			fwds = append(fwds, owned...)
+			return err
 		})
 	})
 	return fwds, err
 }
 
-// DeleteFwd deletes a fwd by hash.
-func (r *Repo) DeleteFwd(hash string) error {
-	return r.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket([]byte(bucketFwds))
-		return b.Delete([]byte(hash))
+// LoadFwds loads the fwds of owner from the DB.
+func (r *Repo) LoadFwds(owner uint32) ([]*ctrlpb.FwdState, error) {
+	var fwds []*ctrlpb.FwdState
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte(bucketOwners)).Bucket(ownerKey(owner))
+		if b == nil {
+			return nil
+		}
+		var err error
+		fwds, err = loadFwds(b, owner)
+		return err
 	})
+	return fwds, err
+}
+
+func loadFwds(b *bbolt.Bucket, owner uint32) ([]*ctrlpb.FwdState, error) {
+	var fwds []*ctrlpb.FwdState
+	err := b.ForEach(func(_, v []byte) error {
+		var f ctrlpb.FwdState
+		if err := proto.Unmarshal(v, &f); err != nil {
+			return err
+		}
+		f.OwnerUid = owner
+		fwds = append(fwds, &f)
+		return nil
+	})
+	return fwds, err
+}
+
+// DeleteFwd deletes a fwd by hash, of any owner, and reports if it existed.
+func (r *Repo) DeleteFwd(hash string) (bool, error) {
+	var found bool
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		return forEachOwner(tx, func(_ uint32, b *bbolt.Bucket) error {
+			if b.Get([]byte(hash)) == nil {
+				return nil
+			}
+			found = true
+			return b.Delete([]byte(hash))
+		})
+	})
+	return found, err
 }
 
 func (r *Repo) DeleteFwds(hashes ...string) error {
@@ -108,22 +207,34 @@ func (r *Repo) DeleteFwds(hashes ...string) error {
 		return nil // Nothing to do
 	}
 	return r.db.Update(func(tx *bbolt.Tx) error {
-		b := tx.Bucket([]byte(bucketFwds))
-		for _, hash := range hashes {
-			if err := b.Delete([]byte(hash)); err != nil {
-				return err
+		return forEachOwner(tx, func(_ uint32, b *bbolt.Bucket) error {
+			for _, hash := range hashes {
+				if err := b.Delete([]byte(hash)); err != nil {
+					return err
+				}
 			}
-		}
-		return nil
+			return nil
+		})
 	})
 }
 
+// NukeFwds deletes the fwds of all owners.
 func (r *Repo) NukeFwds() error {
 	return r.db.Update(func(tx *bbolt.Tx) error {
-		if err := tx.DeleteBucket([]byte(bucketFwds)); err != nil && err != berrors.ErrBucketNotFound {
+		if err := tx.DeleteBucket([]byte(bucketOwners)); err != nil && err != berrors.ErrBucketNotFound {
 			return err
 		}
-		_, err := tx.CreateBucket([]byte(bucketFwds))
+		_, err := tx.CreateBucket([]byte(bucketOwners))
 		return err
 	})
 }
+
+// NukeOwnerFwds deletes the fwds of owner.
+func (r *Repo) NukeOwnerFwds(owner uint32) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.Bucket([]byte(bucketOwners)).DeleteBucket(ownerKey(owner)); err != nil && err != berrors.ErrBucketNotFound {
+			return err
+		}
+		return nil
+	})
+}
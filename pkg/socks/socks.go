@@ -0,0 +1,206 @@
+package socks
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+const (
+	socksVersion = 0x05
+	authVersion  = 0x01
+
+	methodNoAuth       = 0x00
+	methodUserPass     = 0x02
+	methodNoAcceptable = 0xff
+
+	cmdConnect = 0x01
+
+	atypIPv4   = 0x01
+	atypDomain = 0x03
+	atypIPv6   = 0x04
+
+	replySucceeded           = 0x00
+	replyHostUnreachable     = 0x04
+	replyCommandNotSupported = 0x07
+	replyAddrNotSupported    = 0x08
+)
+
+var (
+	ErrAuthFailed = errors.New("socks auth failed")
+)
+
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Server is a minimal SOCKS5 (RFC 1928) server that only supports the CONNECT command.
+type Server struct {
+	// Dial is used to open the upstream connections.
+	Dial DialFunc
+	// Username and Password enable username/password auth (RFC 1929) if Username is set.
+	Username string
+	Password string
+	// ResolveLocal resolves domain names on this host instead of passing them to Dial.
+	ResolveLocal bool
+}
+
+// Handshake negotiates a SOCKS5 session on conn and dials the requested target.
+// On success the upstream connection is returned and the client has been told
+// the request succeeded, so the caller only needs to pipe the two connections.
+func (s *Server) Handshake(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	if err := s.negotiate(conn); err != nil {
+		return nil, err
+	}
+
+	target, err := s.readRequest(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	upstream, err := s.Dial(ctx, "tcp", target)
+	if err != nil {
+		writeReply(conn, replyHostUnreachable)
+		return nil, fmt.Errorf("dial %s: %w", target, err)
+	}
+
+	if err := writeReply(conn, replySucceeded); err != nil {
+		upstream.Close()
+		return nil, err
+	}
+	return upstream, nil
+}
+
+func (s *Server) negotiate(conn net.Conn) error {
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(conn, hdr); err != nil {
+		return err
+	}
+	if hdr[0] != socksVersion {
+		return fmt.Errorf("unsupported socks version %d", hdr[0])
+	}
+	methods := make([]byte, hdr[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return err
+	}
+
+	want := byte(methodNoAuth)
+	if s.Username != "" {
+		want = methodUserPass
+	}
+	for _, m := range methods {
+		if m == want {
+			if _, err := conn.Write([]byte{socksVersion, want}); err != nil {
+				return err
+			}
+			if want == methodUserPass {
+				return s.authenticate(conn)
+			}
+			return nil
+		}
+	}
+
+	conn.Write([]byte{socksVersion, methodNoAcceptable})
+	return errors.New("no acceptable socks auth method")
+}
+
+func (s *Server) authenticate(conn net.Conn) error {
+	hdr := make([]byte, 2)
+	if _, err := io.ReadFull(conn, hdr); err != nil {
+		return err
+	}
+	if hdr[0] != authVersion {
+		return fmt.Errorf("unsupported socks auth version %d", hdr[0])
+	}
+	user := make([]byte, hdr[1])
+	if _, err := io.ReadFull(conn, user); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(conn, hdr[:1]); err != nil {
+		return err
+	}
+	pass := make([]byte, hdr[0])
+	if _, err := io.ReadFull(conn, pass); err != nil {
+		return err
+	}
+
+	userOk := subtle.ConstantTimeCompare(user, []byte(s.Username)) == 1
+	passOk := subtle.ConstantTimeCompare(pass, []byte(s.Password)) == 1
+	if !userOk || !passOk {
+		conn.Write([]byte{authVersion, 0x01})
+		return ErrAuthFailed
+	}
+	_, err := conn.Write([]byte{authVersion, 0x00})
+	return err
+}
+
+// readRequest reads the client request and returns the address to dial.
+func (s *Server) readRequest(ctx context.Context, conn net.Conn) (string, error) {
+	hdr := make([]byte, 4)
+	if _, err := io.ReadFull(conn, hdr); err != nil {
+		return "", err
+	}
+	if hdr[0] != socksVersion {
+		return "", fmt.Errorf("unsupported socks version %d", hdr[0])
+	}
+
+	var host string
+	switch hdr[3] {
+	case atypIPv4, atypIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if hdr[3] == atypIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", err
+		}
+		host = ip.String()
+	case atypDomain:
+		l := make([]byte, 1)
+		if _, err := io.ReadFull(conn, l); err != nil {
+			return "", err
+		}
+		name := make([]byte, l[0])
+		if _, err := io.ReadFull(conn, name); err != nil {
+			return "", err
+		}
+		host = string(name)
+	default:
+		writeReply(conn, replyAddrNotSupported)
+		return "", fmt.Errorf("unsupported address type %d", hdr[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", err
+	}
+
+	if hdr[1] != cmdConnect {
+		writeReply(conn, replyCommandNotSupported)
+		return "", fmt.Errorf("unsupported socks command %d", hdr[1])
+	}
+
+	if hdr[3] == atypDomain && s.ResolveLocal {
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		if err == nil && len(addrs) == 0 {
+			err = errors.New("no addresses found")
+		}
+		if err != nil {
+			writeReply(conn, replyHostUnreachable)
+			return "", fmt.Errorf("resolve %s: %w", host, err)
+		}
+		host = addrs[0]
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// writeReply writes a reply with an unspecified bind address,
+// the address the ssh server used for the channel is not known to us.
+func writeReply(conn net.Conn, code byte) error {
+	_, err := conn.Write([]byte{socksVersion, code, 0x00, atypIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,203 @@
+package socks
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// step is one exchange of a client with the server, send is written and expect is read back.
+type step struct {
+	send   []byte
+	expect []byte
+}
+
+func request(cmd, atyp byte, addr []byte, port uint16) []byte {
+	b := []byte{socksVersion, cmd, 0x00, atyp}
+	if atyp == atypDomain {
+		b = append(b, byte(len(addr)))
+	}
+	b = append(b, addr...)
+	return binary.BigEndian.AppendUint16(b, port)
+}
+
+func userPass(user, pass string) []byte {
+	b := append([]byte{authVersion, byte(len(user))}, user...)
+	b = append(b, byte(len(pass)))
+	return append(b, pass...)
+}
+
+func reply(code byte) []byte {
+	return []byte{socksVersion, code, 0x00, atypIPv4, 0, 0, 0, 0, 0, 0}
+}
+
+var errDial = errors.New("dial failed")
+
+func TestHandshake(t *testing.T) {
+	connect := func(atyp byte, addr []byte, port uint16) step {
+		return step{request(cmdConnect, atyp, addr, port), reply(replySucceeded)}
+	}
+	noAuth := step{[]byte{socksVersion, 1, methodNoAuth}, []byte{socksVersion, methodNoAuth}}
+	offerBoth := step{[]byte{socksVersion, 2, methodNoAuth, methodUserPass}, []byte{socksVersion, methodUserPass}}
+
+	tests := []struct {
+		name       string
+		server     Server
+		steps      []step
+		wantTarget string
+		wantErr    error
+	}{
+		{
+			name:       "no auth ipv4",
+			steps:      []step{noAuth, connect(atypIPv4, []byte{10, 0, 0, 1}, 80)},
+			wantTarget: "10.0.0.1:80",
+		},
+		{
+			name:       "no auth domain",
+			steps:      []step{noAuth, connect(atypDomain, []byte("example.com"), 443)},
+			wantTarget: "example.com:443",
+		},
+		{
+			name:       "no auth ipv6",
+			steps:      []step{noAuth, connect(atypIPv6, net.ParseIP("2001:db8::1"), 22)},
+			wantTarget: "[2001:db8::1]:22",
+		},
+		{
+			name:       "no auth offered with more methods",
+			steps:      []step{{[]byte{socksVersion, 2, methodUserPass, methodNoAuth}, []byte{socksVersion, methodNoAuth}}, connect(atypIPv4, []byte{10, 0, 0, 1}, 80)},
+			wantTarget: "10.0.0.1:80",
+		},
+		{
+			name:       "user pass",
+			server:     Server{Username: "user", Password: "pass"},
+			steps:      []step{offerBoth, {userPass("user", "pass"), []byte{authVersion, 0x00}}, connect(atypIPv4, []byte{10, 0, 0, 1}, 80)},
+			wantTarget: "10.0.0.1:80",
+		},
+		{
+			name:    "wrong password",
+			server:  Server{Username: "user", Password: "pass"},
+			steps:   []step{offerBoth, {userPass("user", "nope"), []byte{authVersion, 0x01}}},
+			wantErr: ErrAuthFailed,
+		},
+		{
+			name:    "wrong user",
+			server:  Server{Username: "user", Password: "pass"},
+			steps:   []step{offerBoth, {userPass("admin", "pass"), []byte{authVersion, 0x01}}},
+			wantErr: ErrAuthFailed,
+		},
+		{
+			name:   "auth required but not offered",
+			server: Server{Username: "user", Password: "pass"},
+			steps:  []step{{[]byte{socksVersion, 1, methodNoAuth}, []byte{socksVersion, methodNoAcceptable}}},
+		},
+		{
+			name:  "socks4",
+			steps: []step{{[]byte{0x04, 1}, nil}},
+		},
+		{
+			name:  "bind command",
+			steps: []step{noAuth, {request(0x02, atypIPv4, []byte{10, 0, 0, 1}, 80), reply(replyCommandNotSupported)}},
+		},
+		{
+			name:  "unknown address type",
+			steps: []step{noAuth, {[]byte{socksVersion, cmdConnect, 0x00, 0x05}, reply(replyAddrNotSupported)}},
+		},
+		{
+			name:       "dial failure",
+			steps:      []step{noAuth, {request(cmdConnect, atypDomain, []byte("unreachable"), 80), reply(replyHostUnreachable)}},
+			wantTarget: "unreachable:80",
+			wantErr:    errDial,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			client.SetDeadline(time.Now().Add(5 * time.Second))
+
+			var dialed string
+			srv := tt.server
+			srv.Dial = func(_ context.Context, _, addr string) (net.Conn, error) {
+				dialed = addr
+				if addr == "unreachable:80" {
+					return nil, errDial
+				}
+				c, _ := net.Pipe()
+				return c, nil
+			}
+
+			errc := make(chan error, 1)
+			go func() {
+				upstream, err := srv.Handshake(context.Background(), server)
+				if upstream != nil {
+					upstream.Close()
+				}
+				server.Close()
+				errc <- err
+			}()
+
+			for i, s := range tt.steps {
+				if _, err := client.Write(s.send); err != nil {
+					t.Fatalf("step %d: write: %v", i, err)
+				}
+				got := make([]byte, len(s.expect))
+				if _, err := io.ReadFull(client, got); err != nil {
+					t.Fatalf("step %d: read: %v", i, err)
+				}
+				if !bytes.Equal(got, s.expect) {
+					t.Fatalf("step %d: got %x, want %x", i, got, s.expect)
+				}
+			}
+
+			err := <-errc
+			if tt.wantTarget != "" && dialed != tt.wantTarget {
+				t.Errorf("dialed %q, want %q", dialed, tt.wantTarget)
+			}
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			case tt.wantTarget == "" && err == nil:
+				t.Error("expected the handshake to fail")
+			case tt.wantTarget != "" && tt.wantErr == nil && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestHandshakeResolveLocal(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var dialed string
+	srv := Server{ResolveLocal: true, Dial: func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed = addr
+		c, _ := net.Pipe()
+		return c, nil
+	}}
+	errc := make(chan error, 1)
+	go func() {
+		_, err := srv.Handshake(context.Background(), server)
+		server.Close()
+		errc <- err
+	}()
+
+	client.Write([]byte{socksVersion, 1, methodNoAuth})
+	io.ReadFull(client, make([]byte, 2))
+	client.Write(request(cmdConnect, atypDomain, []byte("localhost"), 8080))
+	io.ReadFull(client, make([]byte, 10))
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(dialed)
+	if net.ParseIP(host) == nil || port != "8080" {
+		t.Errorf("dialed %q, want localhost resolved to an ip", dialed)
+	}
+}
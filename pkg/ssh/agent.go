@@ -10,7 +10,9 @@ import (
 	"golang.org/x/crypto/ssh/agent"
 )
 
-func GetSSHAgentAuth() (ssh.AuthMethod, error) {
+// GetSSHAgentAuth returns an auth method using the keys of the ssh agent, the keys are paired
+// with their certificates in certs and the certificates held by the agent are checked for validity.
+func GetSSHAgentAuth(certs ...*ssh.Certificate) (ssh.AuthMethod, error) {
 	socket := os.Getenv("SSH_AUTH_SOCK")
 	if socket == "" {
 		return nil, fmt.Errorf("SSH_AUTH_SOCK not found")
@@ -29,5 +31,16 @@ func GetSSHAgentAuth() (ssh.AuthMethod, error) {
 		zap.L().Warn("no signers available from the ssh agent, make sure you add your signers to the ssh agent")
 	}
 
-	return ssh.PublicKeysCallback(agentClient.Signers), nil
+	return ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		signers, err := agentClient.Signers()
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range signers {
+			if cert, ok := s.PublicKey().(*ssh.Certificate); ok {
+				warnValidity(cert, "agent")
+			}
+		}
+		return certSigners(signers, certs), nil
+	}), nil
 }
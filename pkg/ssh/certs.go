@@ -0,0 +1,126 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Phillezi/tunman/utils"
+	"github.com/kevinburke/ssh_config"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+// certSuffix is appended to the path of an identity to find its certificate, like ssh does.
+const certSuffix = "-cert.pub"
+
+// certificateFiles returns the CertificateFile entries of the ssh config for host.
+func certificateFiles(host string) []string {
+	files, err := ssh_config.GetAllStrict(host, "CertificateFile")
+	if err != nil {
+		zap.L().Error("error retrieving CertificateFile", zap.Error(err))
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		if f != "" {
+			paths = append(paths, utils.EvalPath(f))
+		}
+	}
+	return paths
+}
+
+// loadCertificate loads the OpenSSH certificate at path, it has to be in the authorized_keys format of *-cert.pub files.
+func loadCertificate(path string) (*ssh.Certificate, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate %s: %w", path, err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s is a %s key and not a certificate", path, pub.Type())
+	}
+	return cert, nil
+}
+
+// loadCertificates loads the certificates at paths, the conventional -cert.pub files
+// are optional so they are skipped silently if missing, the others are warned about.
+func loadCertificates(paths []string) []*ssh.Certificate {
+	var certs []*ssh.Certificate
+	for _, path := range paths {
+		cert, err := loadCertificate(path)
+		if errors.Is(err, os.ErrNotExist) && strings.HasSuffix(path, certSuffix) {
+			continue
+		} else if err != nil {
+			zap.L().Warn("skipping certificate", zap.String("certFile", path), zap.Error(err))
+			continue
+		}
+		warnValidity(cert, path)
+		certs = append(certs, cert)
+	}
+	return certs
+}
+
+// warnValidity warns if cert can not be used to authenticate right now, servers reject such certificates
+// so the key falls back to being offered without it.
+func warnValidity(cert *ssh.Certificate, source string) {
+	fields := []zap.Field{zap.String("certificate", source), zap.String("keyId", cert.KeyId)}
+	if cert.CertType != ssh.UserCert {
+		zap.L().Warn("certificate is not a user certificate", fields...)
+		return
+	}
+	now := time.Now()
+	if after := int64(cert.ValidAfter); cert.ValidAfter != 0 && now.Unix() < after {
+		zap.L().Warn("certificate is not yet valid", append(fields, zap.String("validAfter", time.Unix(after, 0).Format(time.RFC3339)))...)
+	}
+	if before := int64(cert.ValidBefore); cert.ValidBefore != ssh.CertTimeInfinity && now.Unix() >= before {
+		zap.L().Warn("certificate has expired", append(fields, zap.String("validBefore", time.Unix(before, 0).Format(time.RFC3339)))...)
+	}
+}
+
+// certSigners pairs the keys of signers with their certificates in certs, see withCertificates.
+// Keys offered more than once only count against the auth attempts of the server, so duplicates are dropped.
+func certSigners(signers []ssh.Signer, certs []*ssh.Certificate) []ssh.Signer {
+	seen := make(map[string]bool)
+	var out []ssh.Signer
+	for _, signer := range signers {
+		for _, s := range withCertificates(signer, certs) {
+			key := string(s.PublicKey().Marshal())
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// withCertificates returns the signers of the certificates in certs that belong to the key of signer,
+// followed by signer itself so that servers not trusting the certificates can still accept the key.
+func withCertificates(signer ssh.Signer, certs []*ssh.Certificate) []ssh.Signer {
+	if _, ok := signer.PublicKey().(*ssh.Certificate); ok {
+		// already a certificate, for example one held by the agent
+		return []ssh.Signer{signer}
+	}
+	key := signer.PublicKey().Marshal()
+	var signers []ssh.Signer
+	for _, cert := range certs {
+		if !bytes.Equal(cert.Key.Marshal(), key) {
+			continue
+		}
+		certSigner, err := ssh.NewCertSigner(cert, signer)
+		if err != nil {
+			zap.L().Warn("skipping certificate", zap.String("keyId", cert.KeyId), zap.Error(err))
+			continue
+		}
+		signers = append(signers, certSigner)
+	}
+	return append(signers, signer)
+}
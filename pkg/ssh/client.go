@@ -12,7 +12,14 @@ func createSSHClient(from *ssh.Client, addr string, cfg *ssh.ClientConfig) (*ssh
 	}
 	ncc, chans, reqs, err := ssh.NewClientConn(conn, addr, cfg)
 	if err != nil {
+		conn.Close()
 		return nil, err
 	}
-	return ssh.NewClient(ncc, chans, reqs), nil
+	client := ssh.NewClient(ncc, chans, reqs)
+	// the jump client is only used by this client, close it when this client is done
+	go func() {
+		client.Wait()
+		from.Close()
+	}()
+	return client, nil
 }
@@ -18,6 +18,13 @@ type Target struct {
 	User string
 	Host string
 	Port uint
+	// PromptAuth returns the auth methods that ask the user for answers, like passwords and one-time passwords,
+	// for the server of user@host. They are tried after all other methods.
+	PromptAuth func(user, host string) []ssh.AuthMethod
+	// AskPassphrase asks the user for the passphrase of an encrypted private key, nil if there is no one to ask.
+	AskPassphrase PassphraseFunc
+	// Owner is the local user that encrypted private keys are decrypted for.
+	Owner uint32
 }
 
 func getSSHClientConfig(target *Target, cfgs ...*ssh.ClientConfig) (*ssh.ClientConfig, error) {
@@ -68,13 +75,6 @@ func getSSHClientConfig(target *Target, cfgs ...*ssh.ClientConfig) (*ssh.ClientC
 	// Start building auth methods
 	var authOpts []ssh.AuthMethod
 
-	// Add SSH agent auth if available
-	if agentAuth, err := GetSSHAgentAuth(); err == nil {
-		authOpts = append(authOpts, agentAuth)
-	} else {
-		zap.L().Warn("could not get ssh agent signers", zap.Error(err))
-	}
-
 	// Use IdentityFile from ssh config
 	keyFile, err := ssh_config.GetStrict(target.Host, "IdentityFile")
 	if err != nil {
@@ -82,26 +82,44 @@ func getSSHClientConfig(target *Target, cfgs ...*ssh.ClientConfig) (*ssh.ClientC
 	}
 	if keyFile != "" {
 		keyFile = utils.EvalPath(keyFile)
-		if _, err := os.Stat(keyFile); err == nil {
-			if pk, err := loadPrivateKey(keyFile); err == nil {
-				authOpts = append(authOpts, ssh.PublicKeys(pk))
-			} else {
-				return nil, fmt.Errorf("failed to load private key: %w", err)
+		if _, err := os.Stat(keyFile); err != nil {
+			if keyFile != utils.EvalPath(ssh_config.Default("IdentityFile")) {
+				zap.L().Warn("specified IdentityFile does not exist", zap.String("keyFile", keyFile))
 			}
-		} else if keyFile != utils.EvalPath(ssh_config.Default("IdentityFile")) {
-			zap.L().Warn("specified IdentityFile does not exist", zap.String("keyFile", keyFile))
+			keyFile = ""
 		}
 	}
 
+	// Certificates from CertificateFile and next to the identity, like ssh
+	certFiles := certificateFiles(target.Host)
+	if keyFile != "" {
+		certFiles = append(certFiles, keyFile+certSuffix)
+	}
+	certs := loadCertificates(certFiles)
+
+	// Add SSH agent auth if available
+	if agentAuth, err := GetSSHAgentAuth(certs...); err == nil {
+		authOpts = append(authOpts, agentAuth)
+	} else {
+		zap.L().Warn("could not get ssh agent signers", zap.Error(err))
+	}
+
+	if keyFile != "" {
+		authOpts = append(authOpts, privateKeyAuth(keyFile, certs, target.Owner, target.AskPassphrase))
+	}
+
 	if len(cfg.Auth) == 0 {
 		cfg.Auth = authOpts
 	} else {
 		cfg.Auth = append(cfg.Auth, authOpts...)
 	}
+	if target.PromptAuth != nil {
+		cfg.Auth = append(cfg.Auth, target.PromptAuth(cfg.User, target.Host)...)
+	}
 
 	// HostKeyCallback
 	if !viper.GetBool("insecure") && !viper.GetBool("insecure-skip-hostkey-callback") {
-		hostKeyCallback, err := GetHostKeyCallback()
+		hostKeyCallback, err := GetHostKeyCallback(target.Host)
 		if err != nil {
 			zap.L().Warn("could not get host key callback", zap.Error(err))
 			if !viper.GetBool("insecure") && !viper.GetBool("insecure-skip-hostkey-callback") {
@@ -120,16 +138,16 @@ func getSSHClientConfig(target *Target, cfgs ...*ssh.ClientConfig) (*ssh.ClientC
 	return cfg, nil
 }
 
-// loadPrivateKey loads the SSH private key from the user's .ssh directory.
-func loadPrivateKey(keyPath string) (ssh.Signer, error) {
+// loadPrivateKey loads the SSH private key from the user's .ssh directory, see ParsePrivateKey for encrypted keys.
+func loadPrivateKey(keyPath string, owner uint32, ask PassphraseFunc) (ssh.Signer, error) {
 	key, err := os.ReadFile(keyPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read private key file: %v", err)
 	}
 
-	privateKey, err := ssh.ParsePrivateKey(key)
+	privateKey, err := ParsePrivateKey(key, keyPath, owner, ask)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %v", err)
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
 	}
 
 	return privateKey, nil
@@ -1,19 +1,155 @@
 package ssh
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/Phillezi/tunman/utils"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/knownhosts"
 )
 
-func GetHostKeyCallback() (ssh.HostKeyCallback, error) {
-	userHomeDir, err := os.UserHomeDir()
+// GetHostKeyCallback returns the callback verifying the host keys of host like ssh does with its ssh config,
+// using the UserKnownHostsFile and GlobalKnownHostsFile options, StrictHostKeyChecking and HashKnownHosts.
+// Host certificates signed by the authorities in the @cert-authority lines or by the CA keys configured
+// with host-keys.cas are accepted, keys in @revoked lines are rejected, also when they sign or are signed by a certificate.
+func GetHostKeyCallback(host string) (ssh.HostKeyCallback, error) {
+	cas, err := loadHostCAs(viper.GetStringSlice("host-keys.cas"))
 	if err != nil {
 		return nil, err
 	}
 
-	knownHostsPath := filepath.Join(userHomeDir, ".ssh", "known_hosts")
-	return knownhosts.New(knownHostsPath)
+	userFiles := knownHostsFiles(host, "UserKnownHostsFile")
+	files := existingFiles(append(slices.Clone(userFiles), knownHostsFiles(host, "GlobalKnownHostsFile")...))
+	check, err := newHostKeyChecker(cas, files...)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := strictHostKeyChecking(host)
+	var path string
+	if len(userFiles) > 0 {
+		// like ssh, new keys go to the first user file
+		path = userFiles[0]
+	}
+	hash, err := getConfig(host, "HashKnownHosts")
+	if err != nil {
+		zap.L().Error("error retrieving HashKnownHosts", zap.Error(err))
+	}
+	return withPolicy(check, policy, path, strings.EqualFold(hash, "yes")), nil
+}
+
+// hostKeyChecker checks host certificates against the configured CAs and leaves the other host keys
+// to the known_hosts files, which only revoke certificates themselves and not the keys of their CAs.
+type hostKeyChecker struct {
+	cas      []ssh.PublicKey
+	revoked  map[string]bool
+	fallback ssh.HostKeyCallback
+}
+
+func newHostKeyChecker(cas []ssh.PublicKey, files ...string) (ssh.HostKeyCallback, error) {
+	fallback, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := loadRevoked(files...)
+	if err != nil {
+		return nil, err
+	}
+	c := &hostKeyChecker{cas: cas, revoked: revoked, fallback: fallback}
+	return c.check, nil
+}
+
+func (c *hostKeyChecker) check(addr string, remote net.Addr, key ssh.PublicKey) error {
+	cert, ok := key.(*ssh.Certificate)
+	if ok && c.isRevoked(cert) {
+		return fmt.Errorf("ssh: host certificate %q of %s is revoked", cert.KeyId, addr)
+	}
+	if !ok || !c.isAuthority(cert.SignatureKey) {
+		// plain keys and the certificates of the @cert-authority lines
+		return c.fallback(addr, remote, key)
+	}
+	checker := ssh.CertChecker{
+		IsHostAuthority: func(auth ssh.PublicKey, _ string) bool { return c.isAuthority(auth) },
+		IsRevoked:       c.isRevoked,
+	}
+	return checker.CheckHostKey(addr, remote, key)
+}
+
+func (c *hostKeyChecker) isAuthority(key ssh.PublicKey) bool {
+	if c.revoked[string(key.Marshal())] {
+		return false
+	}
+	for _, ca := range c.cas {
+		if bytes.Equal(ca.Marshal(), key.Marshal()) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRevoked reports if the certificate, the key it certifies or the CA that signed it is revoked.
+func (c *hostKeyChecker) isRevoked(cert *ssh.Certificate) bool {
+	for _, key := range []ssh.PublicKey{cert, cert.Key, cert.SignatureKey} {
+		if c.revoked[string(key.Marshal())] {
+			return true
+		}
+	}
+	return false
+}
+
+// loadHostCAs loads the CA public keys in files, one per line in the authorized_keys format.
+func loadHostCAs(files []string) ([]ssh.PublicKey, error) {
+	var cas []ssh.PublicKey
+	for _, file := range files {
+		b, err := os.ReadFile(utils.EvalPath(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read host CA keys: %w", err)
+		}
+		for i, line := range bytes.Split(b, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 || line[0] == '#' {
+				continue
+			}
+			key, _, _, _, err := ssh.ParseAuthorizedKey(line)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse host CA key at %s:%d: %w", file, i+1, err)
+			}
+			cas = append(cas, key)
+		}
+	}
+	return cas, nil
+}
+
+// loadRevoked returns the keys of the @revoked lines in the known_hosts files, keyed by their wire format.
+func loadRevoked(files ...string) (map[string]bool, error) {
+	revoked := make(map[string]bool)
+	for _, file := range files {
+		rest, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for len(rest) > 0 {
+			var marker string
+			var key ssh.PublicKey
+			marker, _, key, _, rest, err = ssh.ParseKnownHosts(rest)
+			if errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+			if marker == "revoked" {
+				revoked[string(key.Marshal())] = true
+			}
+		}
+	}
+	return revoked, nil
 }
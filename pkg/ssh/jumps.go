@@ -86,7 +86,7 @@ func DialWithJumpChain(target *Target, cfgs ...*ssh.ClientConfig) (*ssh.Client,
 	var client *ssh.Client
 
 	for _, jump := range jumps {
-		jumpTarget := &Target{Host: jump}
+		jumpTarget := &Target{Host: jump, PromptAuth: target.PromptAuth, AskPassphrase: target.AskPassphrase, Owner: target.Owner}
 		cfg, err := getSSHClientConfig(jumpTarget, cfgs...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get SSH config for jump %s: %w", jump, err)
@@ -96,14 +96,23 @@ func DialWithJumpChain(target *Target, cfgs ...*ssh.ClientConfig) (*ssh.Client,
 		port := utils.Or(ssh_config.Get(jump, "Port"), "22")
 		addr := fmt.Sprintf("%s:%s", host, port)
 
-		client, err = createSSHClient(client, addr, cfg)
+		next, err := createSSHClient(client, addr, cfg)
 		if err != nil {
+			if client != nil {
+				client.Close()
+			}
 			return nil, fmt.Errorf("failed to connect to jump %s: %w", jump, err)
 		}
+		client = next
 	}
 
 	// Final target
-	return DialDirect(target, client)
+	final, err := DialDirect(target, client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return final, nil
 }
 
 func DialDirect(target *Target, through *ssh.Client, cfgs ...*ssh.ClientConfig) (*ssh.Client, error) {
@@ -0,0 +1,59 @@
+package ssh
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Phillezi/tunman/internal/defaults"
+	"github.com/Phillezi/tunman/utils"
+	"github.com/kevinburke/ssh_config"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Keepalive returns how often keepalives should be sent to host and how many replies
+// can be missed before the connection is considered dead, an interval of 0 disables keepalives.
+//
+// The daemon wide keepalive.interval and keepalive.count-max are used unless the host sets
+// ServerAliveInterval in the ssh config, entries under keepalive.hosts.<host> take priority over both.
+func Keepalive(host string) (time.Duration, int) {
+	interval := viper.GetDuration("keepalive.interval")
+	countMax := utils.Or(viper.GetInt("keepalive.count-max"), defaults.DefaultKeepaliveCountMax)
+
+	if v, err := ssh_config.GetStrict(host, "ServerAliveInterval"); err == nil {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			interval = time.Duration(secs) * time.Second
+			if v, err := ssh_config.GetStrict(host, "ServerAliveCountMax"); err == nil {
+				if n, err := strconv.Atoi(v); err == nil && n > 0 {
+					countMax = n
+				}
+			}
+		}
+	}
+
+	// viper lowercases keys and splits them on ".", so host entries are looked up by hand
+	for h, v := range viper.GetStringMap("keepalive.hosts") {
+		if !strings.EqualFold(h, host) {
+			continue
+		}
+		hostCfg := cast.ToStringMap(v)
+		if i, ok := hostCfg["interval"]; ok {
+			if d, err := cast.ToDurationE(i); err == nil {
+				interval = d
+			} else {
+				zap.L().Warn("invalid keepalive interval", zap.String("host", host), zap.Error(err))
+			}
+		}
+		if c, ok := hostCfg["count-max"]; ok {
+			if n, err := cast.ToIntE(c); err == nil && n > 0 {
+				countMax = n
+			} else {
+				zap.L().Warn("invalid keepalive count-max", zap.String("host", host), zap.Any("count-max", c))
+			}
+		}
+	}
+
+	return interval, countMax
+}
@@ -0,0 +1,178 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// passphraseTimeout is how long a passphrase command or askpass helper may take to answer.
+	passphraseTimeout = 2 * time.Minute
+	// passphraseAttempts is how many times a passphrase may be entered wrong, like ssh does.
+	passphraseAttempts = 3
+)
+
+// PassphraseFunc returns the passphrase of the encrypted private key described by key, for example its path.
+type PassphraseFunc func(key string) ([]byte, error)
+
+// decrypted holds the signers of encrypted keys so that their passphrases are not asked for on every dial.
+var decrypted = &signerCache{signers: make(map[signerID]*cachedSigner)}
+
+// signerCache keeps decrypted signers in memory for the lifetime configured by keys.cache-lifetime.
+type signerCache struct {
+	mu      sync.Mutex
+	signers map[signerID]*cachedSigner
+}
+
+// signerID identifies a decrypted signer by the hash of the encrypted key and the user it was decrypted for,
+// so that other users of the daemon have to know the passphrase of the same key themselves.
+type signerID struct {
+	owner uint32
+	pem   [sha256.Size]byte
+}
+
+type cachedSigner struct {
+	signer ssh.Signer
+}
+
+func (c *signerCache) get(id signerID) (ssh.Signer, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.signers[id]; ok {
+		return cached.signer, true
+	}
+	return nil, false
+}
+
+func (c *signerCache) put(id signerID, signer ssh.Signer) {
+	lifetime := viper.GetDuration("keys.cache-lifetime")
+	if lifetime <= 0 {
+		return
+	}
+	cached := &cachedSigner{signer: signer}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.signers[id] = cached
+	time.AfterFunc(lifetime, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		// the key may have been decrypted again since
+		if c.signers[id] == cached {
+			delete(c.signers, id)
+		}
+	})
+}
+
+// ParsePrivateKey parses a private key, encrypted keys are decrypted with a passphrase from the configured
+// passphrase command, else from ask and else from the SSH_ASKPASS helper, and cached for owner.
+// A wrong passphrase is asked for again, up to passphraseAttempts times, without the command which would print it again.
+// key describes the key in prompts, ask may be nil if there is no one to ask.
+func ParsePrivateKey(pem []byte, key string, owner uint32, ask PassphraseFunc) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey(pem)
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		return signer, err
+	}
+
+	id := signerID{owner: owner, pem: sha256.Sum256(pem)}
+	if signer, ok := decrypted.get(id); ok {
+		return signer, nil
+	}
+	var wrong error
+	for attempt := range passphraseAttempts {
+		passphrase, err := passphrase(key, ask, attempt == 0)
+		if err != nil {
+			if wrong != nil {
+				return nil, fmt.Errorf("failed to decrypt key %s: %w", key, wrong)
+			}
+			return nil, err
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(pem, passphrase)
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			zap.L().Warn("wrong passphrase for key", zap.String("key", key), zap.Int("attempt", attempt+1))
+			wrong = err
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decrypt key %s: %w", key, err)
+		}
+		decrypted.put(id, signer)
+		return signer, nil
+	}
+	return nil, fmt.Errorf("failed to decrypt key %s after %d attempts: %w", key, passphraseAttempts, wrong)
+}
+
+// passphrase returns the passphrase of key from the first source that has it, the passphrase command is skipped unless useCommand.
+func passphrase(key string, ask PassphraseFunc, useCommand bool) ([]byte, error) {
+	var errs []error
+	if cmd := viper.GetString("keys.passphrase-command"); cmd != "" && useCommand {
+		// the path of the key is passed as $1
+		p, err := runPassphraseCommand("sh", "-c", cmd, "sh", key)
+		if err == nil {
+			return p, nil
+		}
+		errs = append(errs, fmt.Errorf("passphrase command: %w", err))
+	}
+	if ask != nil {
+		p, err := ask(key)
+		if err == nil {
+			return p, nil
+		}
+		errs = append(errs, err)
+	}
+	if askpass := os.Getenv("SSH_ASKPASS"); askpass != "" && os.Getenv("SSH_ASKPASS_REQUIRE") != "never" {
+		p, err := runPassphraseCommand(askpass, fmt.Sprintf("Enter passphrase for key '%s': ", key))
+		if err == nil {
+			return p, nil
+		}
+		errs = append(errs, fmt.Errorf("askpass: %w", err))
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("key %s is encrypted and there is no way to ask for its passphrase", key)
+	}
+	return nil, fmt.Errorf("no passphrase for key %s: %w", key, errors.Join(errs...))
+}
+
+// runPassphraseCommand runs a command that prints a passphrase.
+func runPassphraseCommand(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), passphraseTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return bytes.TrimRight(out, "\r\n"), nil
+}
+
+// privateKeyAuth returns an auth method using the private key file at path and its certificates in certs,
+// encrypted keys are only decrypted for owner once the server is asked to accept them.
+func privateKeyAuth(path string, certs []*ssh.Certificate, owner uint32, ask PassphraseFunc) ssh.AuthMethod {
+	return ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		signer, err := loadPrivateKey(path, owner, ask)
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, err
+		} else if err != nil {
+			// other auth methods may still succeed
+			zap.L().Warn("skipping private key", zap.String("keyFile", path), zap.Error(err))
+			return nil, nil
+		}
+		return withCertificates(signer, certs), nil
+	})
+}
@@ -0,0 +1,99 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+)
+
+// encryptedKey returns a new private key encrypted with passphrase in the OpenSSH format.
+func encryptedKey(t *testing.T, passphrase string) []byte {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+// answers returns a PassphraseFunc answering with passphrases in order and counting how often it was asked.
+func answers(passphrases ...string) (PassphraseFunc, *int) {
+	var asked int
+	return func(string) ([]byte, error) {
+		if asked >= len(passphrases) {
+			return nil, errors.New("no more answers")
+		}
+		asked++
+		return []byte(passphrases[asked-1]), nil
+	}, &asked
+}
+
+func setupKeys(t *testing.T, command string) {
+	t.Helper()
+	t.Setenv("SSH_ASKPASS", "")
+	viper.Set("keys.cache-lifetime", time.Hour)
+	viper.Set("keys.passphrase-command", command)
+	t.Cleanup(func() {
+		viper.Set("keys.cache-lifetime", nil)
+		viper.Set("keys.passphrase-command", nil)
+	})
+}
+
+func TestParsePrivateKeyAttempts(t *testing.T) {
+	tests := []struct {
+		name      string
+		command   string
+		answers   []string
+		wantAsked int
+		wantErr   error
+	}{
+		{name: "right passphrase", answers: []string{"secret"}, wantAsked: 1},
+		{name: "wrong then right", answers: []string{"nope", "secret"}, wantAsked: 2},
+		{name: "wrong every time", answers: []string{"a", "b", "c", "d"}, wantAsked: passphraseAttempts, wantErr: x509.IncorrectPasswordError},
+		{name: "wrong then nothing", answers: []string{"nope"}, wantAsked: 1, wantErr: x509.IncorrectPasswordError},
+		{name: "command", command: "echo secret", wantAsked: 0},
+		{name: "wrong command is not run again", command: "echo nope", answers: []string{"secret"}, wantAsked: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupKeys(t, tt.command)
+			ask, asked := answers(tt.answers...)
+			signer, err := ParsePrivateKey(encryptedKey(t, "secret"), tt.name, 1000, ask)
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && (err != nil || signer == nil):
+				t.Errorf("unexpected error: %v", err)
+			}
+			if *asked != tt.wantAsked {
+				t.Errorf("asked %d times, want %d", *asked, tt.wantAsked)
+			}
+		})
+	}
+}
+
+func TestParsePrivateKeyCache(t *testing.T) {
+	setupKeys(t, "")
+	key := encryptedKey(t, "secret")
+	ask, _ := answers("secret")
+	if _, err := ParsePrivateKey(key, "key", 1000, ask); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParsePrivateKey(key, "key", 1000, nil); err != nil {
+		t.Errorf("the decrypted key was not cached: %v", err)
+	}
+	if _, err := ParsePrivateKey(key, "key", 1001, nil); err == nil {
+		t.Error("the key decrypted for another user was used")
+	}
+}
@@ -0,0 +1,142 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Phillezi/tunman/utils"
+	"github.com/kevinburke/ssh_config"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// hostKeyPolicy is what to do with unknown and changed host keys, the StrictHostKeyChecking of the ssh config.
+type hostKeyPolicy int
+
+const (
+	// strictYes rejects unknown and changed keys, there is no one to ask so "ask" is the same
+	strictYes hostKeyPolicy = iota
+	// strictAcceptNew records and accepts unknown keys but rejects changed keys
+	strictAcceptNew
+	// strictNo records and accepts unknown keys and accepts changed keys with a warning
+	strictNo
+)
+
+// getConfig returns an option of the ssh config of a host, tests replace it to use their own config.
+var getConfig = ssh_config.GetStrict
+
+// knownHostsMu serializes the appends to the known_hosts files of concurrent dials.
+var knownHostsMu sync.Mutex
+
+// strictHostKeyChecking returns the StrictHostKeyChecking policy of host.
+func strictHostKeyChecking(host string) hostKeyPolicy {
+	value, err := getConfig(host, "StrictHostKeyChecking")
+	if err != nil {
+		zap.L().Error("error retrieving StrictHostKeyChecking", zap.Error(err))
+	}
+	switch strings.ToLower(value) {
+	case "accept-new":
+		return strictAcceptNew
+	case "no", "off":
+		return strictNo
+	case "", "yes", "ask":
+		return strictYes
+	default:
+		zap.L().Warn("unknown StrictHostKeyChecking, treating it as yes", zap.String("host", host), zap.String("value", value))
+		return strictYes
+	}
+}
+
+// knownHostsFiles returns the files of the UserKnownHostsFile or GlobalKnownHostsFile option of host, none disables them.
+func knownHostsFiles(host, option string) []string {
+	value, err := getConfig(host, option)
+	if err != nil {
+		zap.L().Error("error retrieving "+option, zap.Error(err))
+	}
+	var files []string
+	for _, f := range strings.Fields(value) {
+		if strings.EqualFold(f, "none") {
+			return nil
+		}
+		files = append(files, utils.EvalPath(f))
+	}
+	return files
+}
+
+// existingFiles returns the files that exist, missing known_hosts files are the same as empty ones.
+func existingFiles(files []string) []string {
+	var out []string
+	for _, f := range files {
+		if _, err := os.Stat(f); err == nil {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// withPolicy applies policy to the unknown and changed host keys rejected by check,
+// unknown keys are accepted by recording them in the known_hosts file at path.
+func withPolicy(check ssh.HostKeyCallback, policy hostKeyPolicy, path string, hash bool) ssh.HostKeyCallback {
+	return func(addr string, remote net.Addr, key ssh.PublicKey) error {
+		err := check(addr, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return err
+		}
+		fingerprint := ssh.FingerprintSHA256(key)
+
+		if len(keyErr.Want) > 0 {
+			if policy != strictNo {
+				return fmt.Errorf("host key of %s has changed to %s, remove the old key from %s:%d if this is expected: %w",
+					addr, fingerprint, keyErr.Want[0].Filename, keyErr.Want[0].Line, err)
+			}
+			zap.L().Warn("host key has changed, accepting it since StrictHostKeyChecking is no",
+				zap.String("host", addr), zap.String("fingerprint", fingerprint))
+			return nil
+		}
+
+		if policy == strictYes {
+			return fmt.Errorf("host key %s of %s is unknown, add it to the known hosts or set StrictHostKeyChecking accept-new: %w", fingerprint, addr, err)
+		}
+		if path == "" {
+			zap.L().Warn("accepting unknown host key without recording it, UserKnownHostsFile is none",
+				zap.String("host", addr), zap.String("fingerprint", fingerprint))
+			return nil
+		}
+		if err := appendKnownHost(path, addr, key, hash); err != nil {
+			return fmt.Errorf("failed to record host key of %s: %w", addr, err)
+		}
+		zap.L().Info("added host key to the known hosts",
+			zap.String("host", addr), zap.String("fingerprint", fingerprint), zap.String("file", path))
+		return nil
+	}
+}
+
+// appendKnownHost appends key of addr to the known_hosts file at path, with the host hashed if hash is set.
+func appendKnownHost(path, addr string, key ssh.PublicKey, hash bool) error {
+	host := knownhosts.Normalize(addr)
+	if hash {
+		host = knownhosts.HashHostname(host)
+	}
+
+	knownHostsMu.Lock()
+	defer knownHostsMu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, knownhosts.Line([]string{host}, key)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
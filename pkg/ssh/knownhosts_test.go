@@ -0,0 +1,243 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kevinburke/ssh_config"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const testHost = "example.com"
+
+// useConfig makes the lookups of the ssh config use config instead of the files of the user.
+func useConfig(t *testing.T, config string) {
+	t.Helper()
+	cfg, err := ssh_config.Decode(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := getConfig
+	getConfig = func(host, key string) (string, error) {
+		value, err := cfg.Get(host, key)
+		if err != nil || value != "" {
+			return value, err
+		}
+		return ssh_config.Default(key), nil
+	}
+	t.Cleanup(func() { getConfig = old })
+}
+
+func hostKey(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// handshake connects to an in-process ssh server with key as its host key and checks it with callback.
+func handshake(t *testing.T, key ssh.Signer, callback ssh.HostKeyCallback) error {
+	t.Helper()
+	// both ends send their version first, which would block on the unbuffered net.Pipe
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	serverCfg := &ssh.ServerConfig{NoClientAuth: true}
+	serverCfg.AddHostKey(key)
+	go func() {
+		server, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer server.Close()
+		conn, chans, reqs, err := ssh.NewServerConn(server, serverCfg)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		go ssh.DiscardRequests(reqs)
+		for ch := range chans {
+			ch.Reject(ssh.Prohibited, "")
+		}
+	}()
+
+	client, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	conn, chans, reqs, err := ssh.NewClientConn(client, testHost+":22", &ssh.ClientConfig{User: "tester", HostKeyCallback: callback})
+	if err != nil {
+		return err
+	}
+	ssh.NewClient(conn, chans, reqs).Close()
+	return nil
+}
+
+func writeKnownHosts(t *testing.T, path string, keys ...ssh.PublicKey) {
+	t.Helper()
+	var lines []string
+	for _, key := range keys {
+		lines = append(lines, knownhosts.Line([]string{testHost}, key))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestHostKeyPolicy(t *testing.T) {
+	key, other := hostKey(t), hostKey(t)
+	tests := []struct {
+		name   string
+		strict string
+		// known is the key already in the second user file, nil if the host is unknown
+		known ssh.PublicKey
+		// wantErr is set if the key should be rejected
+		wantErr bool
+		// wantRecorded is set if the key should be added to the first user file
+		wantRecorded bool
+	}{
+		{name: "yes unknown", strict: "yes", wantErr: true},
+		{name: "yes known", strict: "yes", known: key.PublicKey()},
+		{name: "yes changed", strict: "yes", known: other.PublicKey(), wantErr: true},
+		{name: "ask unknown", strict: "ask", wantErr: true},
+		{name: "default unknown", wantErr: true},
+		{name: "accept-new unknown", strict: "accept-new", wantRecorded: true},
+		{name: "accept-new known", strict: "accept-new", known: key.PublicKey()},
+		{name: "accept-new changed", strict: "accept-new", known: other.PublicKey(), wantErr: true},
+		{name: "no unknown", strict: "no", wantRecorded: true},
+		{name: "no changed", strict: "no", known: other.PublicKey()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			first, second, global := filepath.Join(dir, "known_hosts"), filepath.Join(dir, "known_hosts2"), filepath.Join(dir, "global")
+			if tt.known != nil {
+				writeKnownHosts(t, second, tt.known)
+			}
+			config := fmt.Sprintf("Host %s\n  UserKnownHostsFile %s %s\n  GlobalKnownHostsFile %s\n", testHost, first, second, global)
+			if tt.strict != "" {
+				config += "  StrictHostKeyChecking " + tt.strict + "\n"
+			}
+			useConfig(t, config)
+
+			callback, err := GetHostKeyCallback(testHost)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = handshake(t, key, callback)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+
+			recorded := readFile(t, first)
+			if (recorded != "") != tt.wantRecorded {
+				t.Fatalf("first user file is %q, want recorded %v", recorded, tt.wantRecorded)
+			}
+			if tt.wantRecorded {
+				// the recorded key is known from now on
+				useConfig(t, config+"  StrictHostKeyChecking yes\n")
+				callback, err := GetHostKeyCallback(testHost)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := handshake(t, key, callback); err != nil {
+					t.Errorf("recorded key was rejected: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestHashKnownHosts(t *testing.T) {
+	key := hostKey(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ssh", "known_hosts")
+	config := fmt.Sprintf("Host %s\n  UserKnownHostsFile %s\n  GlobalKnownHostsFile none\n  HashKnownHosts yes\n", testHost, path)
+	useConfig(t, config+"  StrictHostKeyChecking accept-new\n")
+
+	callback, err := GetHostKeyCallback(testHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(t, key, callback); err != nil {
+		t.Fatal(err)
+	}
+	recorded := readFile(t, path)
+	if !strings.HasPrefix(recorded, "|1|") || strings.Contains(recorded, testHost) {
+		t.Fatalf("host is not hashed in %q", recorded)
+	}
+
+	useConfig(t, config+"  StrictHostKeyChecking yes\n")
+	callback, err = GetHostKeyCallback(testHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(t, key, callback); err != nil {
+		t.Errorf("hashed key was rejected: %v", err)
+	}
+	if err := handshake(t, hostKey(t), callback); err == nil {
+		t.Error("changed key of a hashed host was accepted")
+	}
+}
+
+func TestKnownHostsNone(t *testing.T) {
+	useConfig(t, fmt.Sprintf("Host %s\n  UserKnownHostsFile none\n  GlobalKnownHostsFile none\n  StrictHostKeyChecking accept-new\n", testHost))
+	callback, err := GetHostKeyCallback(testHost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(t, hostKey(t), callback); err != nil {
+		t.Errorf("unknown key was rejected without known hosts files: %v", err)
+	}
+}
+
+func TestStrictHostKeyChecking(t *testing.T) {
+	tests := []struct {
+		value string
+		want  hostKeyPolicy
+	}{
+		{value: "", want: strictYes},
+		{value: "yes", want: strictYes},
+		{value: "ask", want: strictYes},
+		{value: "Accept-New", want: strictAcceptNew},
+		{value: "no", want: strictNo},
+		{value: "off", want: strictNo},
+		{value: "maybe", want: strictYes},
+	}
+	for _, tt := range tests {
+		config := "Host " + testHost + "\n"
+		if tt.value != "" {
+			config += "  StrictHostKeyChecking " + tt.value + "\n"
+		}
+		useConfig(t, config)
+		if got := strictHostKeyChecking(testHost); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
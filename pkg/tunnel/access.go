@@ -0,0 +1,86 @@
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	ctrlpb "github.com/Phillezi/tunman/proto"
+)
+
+// unprivilegedPort is the first port that users other than root may bind.
+const unprivilegedPort = 1024
+
+// CheckLocalAccess returns an error if the user uid may not use the local side of ap, the socket or port
+// it listens on or, for remote forwards, the socket or port it dials.
+// The daemon may run as root for other users, so their forwards are checked against their own permissions,
+// root and the user running the daemon may use anything the daemon can.
+func CheckLocalAccess(uid uint32, ap AddressPair) error {
+	if trusted(uid) {
+		return nil
+	}
+	if ap.Kind == ctrlpb.FwdKind_FWD_REMOTE {
+		if network(ap.LocalAddr) == "unix" {
+			return checkSocketDial(uid, ap.LocalAddr)
+		}
+		return checkPort(ap.LocalAddr, true)
+	}
+	if network(ap.LocalAddr) == "unix" {
+		return checkSocketPath(uid, ap.LocalAddr)
+	}
+	return checkPort(ap.LocalAddr, false)
+}
+
+// trusted reports if uid is root or the user running the daemon.
+func trusted(uid uint32) bool {
+	return uid == 0 || uid == uint32(os.Geteuid())
+}
+
+// checkPort rejects privileged ports, when dialing only those of local addresses,
+// the other local ports can be connected to by every user anyway.
+func checkPort(addr string, dial bool) error {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := net.LookupPort("tcp", p)
+	if err != nil {
+		return err
+	}
+	if port == 0 || port >= unprivilegedPort {
+		return nil
+	}
+	if !dial {
+		return fmt.Errorf("only root may listen on the privileged port %d", port)
+	}
+	if isLocalHost(host) {
+		return fmt.Errorf("only root may forward to the privileged local port %d", port)
+	}
+	return nil
+}
+
+// isLocalHost reports if host is an address of this machine.
+func isLocalHost(host string) bool {
+	if host == "" {
+		return true
+	}
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		var err error
+		if ips, err = net.LookupIP(host); err != nil {
+			return false
+		}
+	}
+	addrs, _ := net.InterfaceAddrs()
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsUnspecified() {
+			return true
+		}
+		for _, a := range addrs {
+			if n, ok := a.(*net.IPNet); ok && n.IP.Equal(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
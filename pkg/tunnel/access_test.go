@@ -0,0 +1,107 @@
+//go:build unix
+
+package tunnel
+
+import (
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	ctrlpb "github.com/Phillezi/tunman/proto"
+)
+
+func TestCheckLocalAccess(t *testing.T) {
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("no nobody user:", err)
+	}
+	n, err := strconv.ParseUint(nobody.Uid, 10, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid := uint32(n)
+	if uid == uint32(os.Geteuid()) {
+		t.Skip("running as nobody")
+	}
+
+	dir, err := os.MkdirTemp("", "tunman-access")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	shared := filepath.Join(dir, "shared")
+	private := filepath.Join(dir, "private")
+	for _, d := range []string{shared, private} {
+		if err := os.Mkdir(d, 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for d, mode := range map[string]os.FileMode{dir: 0o755, shared: 0o777} {
+		if err := os.Chmod(d, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	taken := filepath.Join(shared, "taken.sock")
+	l, err := net.Listen("unix", taken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := os.Chmod(taken, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	open := filepath.Join(shared, "open.sock")
+	hidden := filepath.Join(private, "hidden.sock")
+	for _, path := range []string{open, hidden} {
+		l, err := net.Listen("unix", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		if err := os.Chmod(path, 0o777); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	local := func(addr string) AddressPair {
+		return AddressPair{Kind: ctrlpb.FwdKind_FWD_LOCAL, LocalAddr: addr, RemoteAddr: "localhost:80"}
+	}
+	reverse := func(addr string) AddressPair {
+		return AddressPair{Kind: ctrlpb.FwdKind_FWD_REMOTE, LocalAddr: addr, RemoteAddr: "0.0.0.0:8080"}
+	}
+	tests := []struct {
+		name string
+		uid  uint32
+		ap   AddressPair
+		want bool
+	}{
+		{name: "root", uid: 0, ap: local("127.0.0.1:80"), want: true},
+		{name: "unprivileged port", uid: uid, ap: local("127.0.0.1:8080"), want: true},
+		{name: "any port", uid: uid, ap: local("127.0.0.1:0"), want: true},
+		{name: "privileged port", uid: uid, ap: local("127.0.0.1:80"), want: false},
+		{name: "privileged port by name", uid: uid, ap: local(":http"), want: false},
+		{name: "dynamic privileged port", uid: uid, ap: AddressPair{Kind: ctrlpb.FwdKind_FWD_DYNAMIC, LocalAddr: "127.0.0.1:443"}, want: false},
+		{name: "socket in shared dir", uid: uid, ap: local(filepath.Join(shared, "new.sock")), want: true},
+		{name: "socket in private dir", uid: uid, ap: local(filepath.Join(private, "new.sock")), want: false},
+		{name: "socket of another user", uid: uid, ap: local(taken), want: false},
+		{name: "reverse to remote privileged port", uid: uid, ap: reverse("192.0.2.1:22"), want: true},
+		{name: "reverse to local port", uid: uid, ap: reverse("127.0.0.1:8080"), want: true},
+		{name: "reverse to local privileged port", uid: uid, ap: reverse("localhost:22"), want: false},
+		{name: "reverse to open socket", uid: uid, ap: reverse(open), want: true},
+		{name: "reverse to closed socket", uid: uid, ap: reverse(taken), want: false},
+		{name: "reverse to socket in private dir", uid: uid, ap: reverse(hidden), want: false},
+		{name: "reverse to missing socket", uid: uid, ap: reverse(filepath.Join(shared, "missing.sock")), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckLocalAccess(tt.uid, tt.ap)
+			if got := err == nil; got != tt.want {
+				t.Errorf("CheckLocalAccess(%d, %s) = %v, want allowed %v", tt.uid, tt.ap.LocalAddr, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+//go:build unix
+
+package tunnel
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+)
+
+const (
+	permWrite  os.FileMode = 2
+	permSearch os.FileMode = 1
+)
+
+// userCred is the identity file permissions are checked for.
+type userCred struct {
+	uid  uint32
+	gids []uint32
+}
+
+func lookupCred(uid uint32) (userCred, error) {
+	u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+	if err != nil {
+		return userCred{}, fmt.Errorf("failed to look up uid %d: %w", uid, err)
+	}
+	groups, err := u.GroupIds()
+	if err != nil {
+		return userCred{}, fmt.Errorf("failed to look up the groups of uid %d: %w", uid, err)
+	}
+	c := userCred{uid: uid}
+	for _, g := range groups {
+		if gid, err := strconv.ParseUint(g, 10, 32); err == nil {
+			c.gids = append(c.gids, uint32(gid))
+		}
+	}
+	return c, nil
+}
+
+// may reports if c is granted perm by the mode bits of fi.
+func (c userCred) may(fi os.FileInfo, perm os.FileMode) bool {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	mode := fi.Mode().Perm()
+	switch {
+	case st.Uid == c.uid:
+		mode >>= 6
+	case slices.Contains(c.gids, st.Gid):
+		mode >>= 3
+	}
+	return mode&perm == perm
+}
+
+// owns reports if fi is owned by c.
+func (c userCred) owns(fi os.FileInfo) bool {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	return ok && st.Uid == c.uid
+}
+
+// reach checks that c may search every directory on the way to dir.
+func (c userCred) reach(dir string) error {
+	for d := dir; ; d = filepath.Dir(d) {
+		fi, err := os.Stat(d)
+		if err != nil {
+			return err
+		}
+		if !c.may(fi, permSearch) {
+			return fmt.Errorf("uid %d may not access %s", c.uid, d)
+		}
+		if d == filepath.Dir(d) {
+			return nil
+		}
+	}
+}
+
+// checkSocketPath checks that uid may create a socket at path, it has to be allowed to write to the directory
+// and an existing socket, which is removed if it is stale, has to be its own.
+func checkSocketPath(uid uint32, path string) error {
+	c, err := lookupCred(uid)
+	if err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	dir := filepath.Dir(path)
+	if err := c.reach(dir); err != nil {
+		return err
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if !c.may(fi, permWrite) {
+		return fmt.Errorf("uid %d may not create sockets in %s", uid, dir)
+	}
+	if fi, err := os.Lstat(path); err == nil && !c.owns(fi) {
+		return fmt.Errorf("%s is not owned by uid %d", path, uid)
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// checkSocketDial checks that uid may connect to the socket at path,
+// a socket that does not exist yet is checked again when it is dialed.
+func checkSocketDial(uid uint32, path string) error {
+	c, err := lookupCred(uid)
+	if err != nil {
+		return err
+	}
+	path = filepath.Clean(path)
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := c.reach(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if !c.may(fi, permWrite) {
+		return fmt.Errorf("uid %d may not connect to %s", uid, path)
+	}
+	return nil
+}
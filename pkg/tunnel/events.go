@@ -0,0 +1,52 @@
+package tunnel
+
+import (
+	"net"
+	"strings"
+
+	"github.com/Phillezi/tunman/pkg/ser"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+)
+
+// WithEvents returns an option to publish the events of the tunnel and its forwards.
+func WithEvents(publish func(*ctrlpb.Event)) ConfigOption {
+	return func(cfg *TunnelOpts) error {
+		cfg.publish = publish
+		return nil
+	}
+}
+
+// emit publishes an event of the tunnel, fwdID is empty for events of the whole tunnel.
+func (t *Tunnel) emit(typ ctrlpb.EventType, fwdID string, peer net.Addr, err error) {
+	if t.publish == nil {
+		return
+	}
+	ev := &ctrlpb.Event{
+		Type:     typ,
+		TunnelId: t.Hash(),
+		Host:     t.uID.Host,
+		OwnerUid: t.uID.OwnerUID,
+	}
+	if fwdID != "" {
+		ev.FwdId = ser.Ser(ev.TunnelId, fwdID)
+	}
+	if peer != nil {
+		ev.Peer = peer.String()
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	t.publish(ev)
+}
+
+// dialFailed publishes an event if a dial failed because the server rejected our credentials.
+func (t *Tunnel) dialFailed(err error) {
+	if isAuthError(err) {
+		t.emit(ctrlpb.EventType_EVENT_AUTH_FAILED, "", nil, err)
+	}
+}
+
+// isAuthError reports if err is the error returned by ssh when no auth method was accepted.
+func isAuthError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "unable to authenticate")
+}
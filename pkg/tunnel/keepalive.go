@@ -0,0 +1,95 @@
+package tunnel
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sshutils "github.com/Phillezi/tunman/pkg/ssh"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	errKeepaliveTimeout = errors.New("keepalive timed out")
+)
+
+// keepalive sends keepalive@openssh.com requests on client and records the round trip time,
+// the client is closed after too many missed replies so that the tunnel reconnects.
+// Only one request is in flight at a time, a tick while it is still unanswered counts as a missed reply.
+func (t *Tunnel) keepalive(client *ssh.Client) {
+	interval, countMax := sshutils.Keepalive(t.uID.Host)
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		missed  int
+		pending <-chan pingResult
+	)
+	miss := func(err error) bool {
+		missed++
+		t.logger().Warn("missed keepalive", zap.Int("missed", missed), zap.Int("countMax", countMax), zap.Error(err))
+		if missed < countMax {
+			return false
+		}
+		t.logger().Warn("peer not responding to keepalives, closing connection")
+		t.clientMu.Lock()
+		t.closeReason = fmt.Errorf("no reply to %d keepalives", missed)
+		t.clientMu.Unlock()
+		// also unblocks the pending request
+		client.Close()
+		return true
+	}
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case r := <-pending:
+			pending = nil
+			if r.err != nil {
+				if miss(r.err) {
+					return
+				}
+				continue
+			}
+			missed = 0
+			t.clientMu.Lock()
+			t.rtt = r.rtt
+			t.clientMu.Unlock()
+		case <-ticker.C:
+			if t.getClient() != client {
+				return
+			}
+			if pending != nil {
+				if miss(errKeepaliveTimeout) {
+					return
+				}
+				continue
+			}
+			pending = ping(client)
+		}
+	}
+}
+
+type pingResult struct {
+	rtt time.Duration
+	err error
+}
+
+// ping sends a keepalive request, the result is sent on the returned channel once the reply arrives.
+// Servers usually reply with a failure, but any reply means the peer is alive.
+func ping(client *ssh.Client) <-chan pingResult {
+	start := time.Now()
+	// buffered so that the reply to an abandoned request does not block
+	result := make(chan pingResult, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		result <- pingResult{rtt: time.Since(start), err: err}
+	}()
+	return result
+}
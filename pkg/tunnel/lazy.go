@@ -0,0 +1,127 @@
+package tunnel
+
+import (
+	"time"
+
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"go.uber.org/zap"
+)
+
+// WithLazy returns an option to not dial the ssh connection until the first connection is accepted.
+func WithLazy() ConfigOption {
+	return func(cfg *TunnelOpts) error {
+		cfg.lazy = true
+		return nil
+	}
+}
+
+// WithIdleTimeout returns an option to close the ssh connection of a tunnel that only has lazy forwards
+// after it has been unused for d, 0 keeps it open.
+func WithIdleTimeout(d time.Duration) ConfigOption {
+	return func(cfg *TunnelOpts) error {
+		cfg.idleTimeout = d
+		return nil
+	}
+}
+
+// wake dials the ssh connection of an idle tunnel, concurrent callers wait for the same dial.
+func (t *Tunnel) wake() error {
+	t.wakeMu.Lock()
+	defer t.wakeMu.Unlock()
+
+	t.clientMu.RLock()
+	idle := t.idle
+	t.clientMu.RUnlock()
+	if !idle {
+		return nil
+	}
+
+	t.logger().Info("connecting idle tunnel")
+	t.setFwdsStatus(ctrlpb.FwdStatus_FWD_DIALING, nil)
+	client, err := t.dial()
+	if err != nil {
+		t.clientMu.Lock()
+		t.lastErr = err
+		t.clientMu.Unlock()
+		t.setFwdsStatus(ctrlpb.FwdStatus_FWD_PAUSED, err)
+		t.dialFailed(err)
+		return err
+	}
+	if err := t.ctx.Err(); err != nil {
+		client.Close()
+		return err
+	}
+	t.setClient(client)
+	t.emit(ctrlpb.EventType_EVENT_TUNNEL_DIALED, "", nil, nil)
+	return nil
+}
+
+// allLazy reports if the tunnel has forwards and all of them are lazy.
+func (t *Tunnel) allLazy() bool {
+	t.connMu.RLock()
+	defer t.connMu.RUnlock()
+	if len(t.conns) == 0 {
+		return false
+	}
+	for _, c := range t.conns {
+		if !c.AddrPair.Lazy {
+			return false
+		}
+	}
+	return true
+}
+
+// lastUsed returns when a forward of the tunnel was last used,
+// ok is false if a forward is in use or if not all forwards are lazy.
+func (t *Tunnel) lastUsed() (last time.Time, ok bool) {
+	t.connMu.RLock()
+	defer t.connMu.RUnlock()
+	if len(t.conns) == 0 {
+		return time.Time{}, false
+	}
+	for _, c := range t.conns {
+		if !c.AddrPair.Lazy || c.Stats.ActiveConns.Load() > 0 {
+			return time.Time{}, false
+		}
+		if la := c.Stats.LastActivity(); la.After(last) {
+			last = la
+		}
+	}
+	return last, true
+}
+
+// idleLoop closes the ssh connection once the lazy forwards of the tunnel have been unused for the idle timeout.
+func (t *Tunnel) idleLoop() {
+	ticker := time.NewTicker(max(t.idleTimeout/4, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// clientMu is held while checking so that connections accepted after the check
+		// see the tunnel as idle and wake it up again
+		t.clientMu.Lock()
+		last, ok := t.lastUsed()
+		if last.Before(t.connectedAt) {
+			last = t.connectedAt
+		}
+		client := t.client
+		if !ok || client == nil || time.Since(last) < t.idleTimeout {
+			t.clientMu.Unlock()
+			continue
+		}
+		t.client = nil
+		t.up = make(chan struct{})
+		t.idle = true
+		t.rtt = 0
+		t.clientMu.Unlock()
+
+		t.logger().Info("closing idle ssh connection", zap.Duration("idleTimeout", t.idleTimeout))
+		client.Close()
+		t.setFwdsStatus(ctrlpb.FwdStatus_FWD_PAUSED, nil)
+	}
+}
@@ -0,0 +1,135 @@
+package tunnel
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Phillezi/tunman/log"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"go.uber.org/zap"
+)
+
+// maxTransitions is how many transitions are kept per forward.
+const maxTransitions = 16
+
+// Lifecycle tracks the status of a forward, the last error and the latest transitions.
+type Lifecycle struct {
+	mu          sync.Mutex
+	id          string
+	status      ctrlpb.FwdStatus
+	lastErr     string
+	transitions []*ctrlpb.FwdTransition
+}
+
+// NewLifecycle returns the lifecycle of a new pending forward.
+func NewLifecycle(id string) *Lifecycle {
+	l := &Lifecycle{id: id}
+	l.record(ctrlpb.FwdStatus_FWD_PENDING, nil)
+	return l
+}
+
+// Set moves the forward to status, err is recorded as the last error if set.
+func (l *Lifecycle) Set(status ctrlpb.FwdStatus, err error) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.status == status && err == nil {
+		return
+	}
+	l.record(status, err)
+}
+
+// Move moves the forward to status only if its current status is one of from.
+func (l *Lifecycle) Move(status ctrlpb.FwdStatus, err error, from ...ctrlpb.FwdStatus) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.status == status || !slices.Contains(from, l.status) {
+		return
+	}
+	l.record(status, err)
+}
+
+func (l *Lifecycle) record(status ctrlpb.FwdStatus, err error) {
+	tr := &ctrlpb.FwdTransition{Status: status, At: time.Now().Unix()}
+	if err != nil {
+		tr.Error = err.Error()
+		l.lastErr = tr.Error
+	}
+	if l.status != status {
+		zap.L().Debug("fwd status changed", zap.String(log.FwdKey, l.id), zap.Stringer("from", l.status), zap.Stringer("to", status), zap.Error(err))
+	}
+	l.status = status
+	l.transitions = append(l.transitions, tr)
+	if len(l.transitions) > maxTransitions {
+		l.transitions = slices.Delete(l.transitions, 0, len(l.transitions)-maxTransitions)
+	}
+}
+
+// Status returns the current status of the forward.
+func (l *Lifecycle) Status() ctrlpb.FwdStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.status
+}
+
+// Fill sets the status, last error and transitions of fwd.
+func (l *Lifecycle) Fill(fwd *ctrlpb.Fwd) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fwd.Status = l.status
+	fwd.LastError = l.lastErr
+	fwd.Transitions = slices.Clone(l.transitions)
+}
+
+// listening is the status of a serving forward given the state of the ssh connection.
+func (t *Tunnel) listening() ctrlpb.FwdStatus {
+	t.clientMu.RLock()
+	defer t.clientMu.RUnlock()
+	switch {
+	case t.client != nil:
+		return ctrlpb.FwdStatus_FWD_LISTENING
+	case t.idle:
+		return ctrlpb.FwdStatus_FWD_PAUSED
+	default:
+		return ctrlpb.FwdStatus_FWD_RECONNECTING
+	}
+}
+
+// setFwdsStatus moves the serving forwards of the tunnel to status, remote forwards
+// are skipped when they come back up since they set their own status once they listen again.
+func (t *Tunnel) setFwdsStatus(status ctrlpb.FwdStatus, err error) {
+	t.connMu.RLock()
+	defer t.connMu.RUnlock()
+	serving := []ctrlpb.FwdStatus{
+		ctrlpb.FwdStatus_FWD_DIALING,
+		ctrlpb.FwdStatus_FWD_LISTENING,
+		ctrlpb.FwdStatus_FWD_DEGRADED,
+		ctrlpb.FwdStatus_FWD_RECONNECTING,
+		ctrlpb.FwdStatus_FWD_PAUSED,
+	}
+	for _, c := range t.conns {
+		if status == ctrlpb.FwdStatus_FWD_LISTENING && c.AddrPair.Kind == ctrlpb.FwdKind_FWD_REMOTE {
+			continue
+		}
+		c.Lifecycle.Move(status, err, serving...)
+	}
+}
+
+// dialed updates the status and dial latency of the forward after dialing its target since start.
+func (f *FwdConn) dialed(start time.Time, err error) {
+	if f.observeDial != nil && err == nil {
+		f.observeDial(time.Since(start))
+	}
+	if err != nil {
+		f.Stats.DialFailures.Add(1)
+		f.Lifecycle.Move(ctrlpb.FwdStatus_FWD_DEGRADED, err, ctrlpb.FwdStatus_FWD_LISTENING, ctrlpb.FwdStatus_FWD_DEGRADED)
+		return
+	}
+	f.Lifecycle.Move(ctrlpb.FwdStatus_FWD_LISTENING, nil, ctrlpb.FwdStatus_FWD_DEGRADED)
+}
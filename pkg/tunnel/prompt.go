@@ -0,0 +1,77 @@
+package tunnel
+
+import (
+	"fmt"
+
+	sshutils "github.com/Phillezi/tunman/pkg/ssh"
+	"golang.org/x/crypto/ssh"
+)
+
+// promptAttempts is how many times a password or challenge may be answered wrong, like ssh does.
+const promptAttempts = 3
+
+// Challenge is a question of an ssh server for the user opening a tunnel.
+type Challenge struct {
+	User        string
+	Host        string
+	Name        string
+	Instruction string
+	Questions   []string
+	// Echos reports for each question if its answer may be shown while it is typed.
+	Echos []bool
+}
+
+// Prompter asks the user opening a tunnel to answer a challenge, it returns an answer per question.
+type Prompter func(Challenge) ([]string, error)
+
+// WithPrompter returns an option to authenticate with keyboard-interactive challenges, like one-time passwords,
+// and passwords answered by prompt. They are tried after all other auth methods, also on jump hosts.
+func WithPrompter(prompt Prompter) ConfigOption {
+	return func(cfg *TunnelOpts) error {
+		cfg.prompt = prompt
+		return nil
+	}
+}
+
+// promptAuth returns the auth methods that ask prompt for the answers to the challenges of the server of user@host.
+func promptAuth(user, host string, prompt Prompter) []ssh.AuthMethod {
+	challenge := func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+		if len(questions) == 0 && name == "" && instruction == "" {
+			// some servers finish with an empty challenge
+			return nil, nil
+		}
+		answers, err := prompt(Challenge{User: user, Host: host, Name: name, Instruction: instruction, Questions: questions, Echos: echos})
+		if err != nil {
+			return nil, err
+		}
+		if len(answers) != len(questions) {
+			return nil, fmt.Errorf("got %d answers to %d questions", len(answers), len(questions))
+		}
+		return answers, nil
+	}
+	password := func() (string, error) {
+		answers, err := challenge("", "", []string{fmt.Sprintf("%s@%s's password: ", user, host)}, []bool{false})
+		if err != nil {
+			return "", err
+		}
+		return answers[0], nil
+	}
+	return []ssh.AuthMethod{
+		ssh.RetryableAuthMethod(ssh.KeyboardInteractive(challenge), promptAttempts),
+		ssh.RetryableAuthMethod(ssh.PasswordCallback(password), promptAttempts),
+	}
+}
+
+// passphrasePrompt returns a sshutils.PassphraseFunc that asks prompt for the passphrases of encrypted keys.
+func passphrasePrompt(prompt Prompter) sshutils.PassphraseFunc {
+	return func(key string) ([]byte, error) {
+		answers, err := prompt(Challenge{Questions: []string{fmt.Sprintf("Enter passphrase for key '%s': ", key)}, Echos: []bool{false}})
+		if err != nil {
+			return nil, err
+		}
+		if len(answers) != 1 {
+			return nil, fmt.Errorf("got %d answers to 1 question", len(answers))
+		}
+		return []byte(answers[0]), nil
+	}
+}
@@ -0,0 +1,212 @@
+package tunnel
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/Phillezi/tunman/internal/defaults"
+	sshutils "github.com/Phillezi/tunman/pkg/ssh"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+	"github.com/Phillezi/tunman/utils"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// reconnectDialWait is how long a dial waits for a reconnecting tunnel.
+	reconnectDialWait = 10 * time.Second
+	// relistenRetry is the delay between attempts to listen again on the remote after a reconnect.
+	relistenRetry = time.Second
+)
+
+// backoff is an exponential backoff with jitter.
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+}
+
+var defaultBackoff = backoff{initial: defaults.DefaultReconnectInitialBackoff, max: defaults.DefaultReconnectMaxBackoff}
+
+// delay returns the time to wait before the attempt:th (starting at 1) retry,
+// the exponential delay is capped at max and then jittered to between 50% and 100% of it.
+func (b backoff) delay(attempt uint32) time.Duration {
+	d := b.initial
+	for i := uint32(1); i < attempt && d < b.max; i++ {
+		d *= 2
+	}
+	d = min(d, b.max)
+	return d/2 + rand.N(d/2+1)
+}
+
+// WithReconnectBackoff returns an option to set the backoff used when redialing a lost connection.
+func WithReconnectBackoff(initialDelay, maxDelay time.Duration) ConfigOption {
+	return func(cfg *TunnelOpts) error {
+		if initialDelay > 0 {
+			cfg.backoff.initial = initialDelay
+		}
+		if maxDelay > 0 {
+			cfg.backoff.max = maxDelay
+		}
+		cfg.backoff.max = max(cfg.backoff.max, cfg.backoff.initial)
+		return nil
+	}
+}
+
+// dial dials the whole jump chain to the target using a fresh copy of the base config.
+func (t *Tunnel) dial() (*ssh.Client, error) {
+	cfg := t.baseCfg
+	cfg.Auth = slices.Clone(t.baseCfg.Auth)
+
+	target := &sshutils.Target{
+		User:  t.uID.User,
+		Host:  t.uID.Host,
+		Port:  t.uID.Port,
+		Owner: t.uID.OwnerUID,
+	}
+	if t.prompt != nil {
+		target.PromptAuth = func(user, host string) []ssh.AuthMethod { return promptAuth(user, host, t.prompt) }
+		target.AskPassphrase = passphrasePrompt(t.prompt)
+	}
+	return sshutils.DialWithJumpChain(target, &cfg)
+}
+
+func (t *Tunnel) getClient() *ssh.Client {
+	t.clientMu.RLock()
+	defer t.clientMu.RUnlock()
+	return t.client
+}
+
+// setClient swaps in a connected client and starts watching it.
+func (t *Tunnel) setClient(client *ssh.Client) {
+	t.clientMu.Lock()
+	t.client = client
+	t.idle = false
+	t.connectedAt = time.Now()
+	close(t.up)
+	t.clientMu.Unlock()
+	t.setFwdsStatus(ctrlpb.FwdStatus_FWD_LISTENING, nil)
+
+	go t.watch(client)
+	go t.keepalive(client)
+}
+
+// takeClient removes the current client from the tunnel and returns it.
+func (t *Tunnel) takeClient() *ssh.Client {
+	t.clientMu.Lock()
+	defer t.clientMu.Unlock()
+	client := t.client
+	if client != nil {
+		t.client = nil
+		t.up = make(chan struct{})
+	}
+	return client
+}
+
+// waitConnected blocks until the tunnel has a connected client or ctx is done.
+func (t *Tunnel) waitConnected(ctx context.Context) error {
+	t.clientMu.RLock()
+	up := t.up
+	t.clientMu.RUnlock()
+
+	select {
+	case <-up:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// watch waits for the client to disconnect and reconnects unless the tunnel was closed.
+func (t *Tunnel) watch(client *ssh.Client) {
+	err := client.Wait()
+	if t.ctx.Err() != nil {
+		return
+	}
+
+	t.clientMu.Lock()
+	if t.client != client {
+		// already replaced or taken by Close
+		t.clientMu.Unlock()
+		return
+	}
+	t.client = nil
+	t.up = make(chan struct{})
+	// prefer the reason if the client was closed deliberately, e.g. by the keepalive
+	t.lastErr = utils.Or(t.closeReason, err)
+	lostErr := t.lastErr
+	t.closeReason = nil
+	t.rtt = 0
+	// lazy forwards will wake the tunnel again when they need it
+	t.idle = t.allLazy()
+	idle := t.idle
+	t.clientMu.Unlock()
+	client.Close()
+	t.emit(ctrlpb.EventType_EVENT_TUNNEL_LOST, "", nil, lostErr)
+
+	if idle {
+		t.setFwdsStatus(ctrlpb.FwdStatus_FWD_PAUSED, err)
+		t.logger().Warn("ssh connection lost, tunnel is idle until needed", zap.Error(err))
+		return
+	}
+	t.setFwdsStatus(ctrlpb.FwdStatus_FWD_RECONNECTING, err)
+	t.logger().Warn("ssh connection lost, reconnecting", zap.Error(err))
+	t.reconnect()
+}
+
+// reconnect redials the jump chain with backoff until it succeeds or the tunnel is closed.
+func (t *Tunnel) reconnect() {
+	for attempt := uint32(1); ; attempt++ {
+		delay := t.backoff.delay(attempt)
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		t.clientMu.Lock()
+		t.reconnectAttempts++
+		t.clientMu.Unlock()
+
+		client, err := t.dial()
+		if err != nil {
+			t.clientMu.Lock()
+			t.lastErr = err
+			t.clientMu.Unlock()
+			t.dialFailed(err)
+			t.logger().Warn("reconnect failed", zap.Uint32("attempt", attempt), zap.Error(err))
+			continue
+		}
+		if t.ctx.Err() != nil {
+			client.Close()
+			return
+		}
+
+		t.logger().Info("reconnected", zap.Uint32("attempts", attempt))
+		t.setClient(client)
+		t.emit(ctrlpb.EventType_EVENT_TUNNEL_RECONNECTED, "", nil, nil)
+		return
+	}
+}
+
+// relisten waits for the tunnel to reconnect and listens on the remote again.
+func (t *Tunnel) relisten(ctx context.Context, fwd *FwdConn, listen func(AddressPair) (net.Listener, error)) (net.Listener, error) {
+	for {
+		if err := t.waitConnected(ctx); err != nil {
+			return nil, err
+		}
+		listener, err := listen(fwd.AddrPair)
+		if err == nil {
+			fwd.log.Info("listening on remote again")
+			return listener, nil
+		}
+		fwd.log.Debug("failed to listen on remote, retrying", zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(relistenRetry):
+		}
+	}
+}
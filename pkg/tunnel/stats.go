@@ -0,0 +1,126 @@
+package tunnel
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/Phillezi/tunman/pkg/ser"
+	ctrlpb "github.com/Phillezi/tunman/proto"
+)
+
+// FwdStats holds the traffic counters of a forward.
+// In and out are seen from the listening side, in is what the accepted
+// connections sent and out is what was sent back to them.
+type FwdStats struct {
+	BytesIn      atomic.Uint64
+	BytesOut     atomic.Uint64
+	ActiveConns  atomic.Int64
+	TotalConns   atomic.Uint64
+	DialFailures atomic.Uint64
+	// Rejected counts the connections refused by the acl of the forward
+	Rejected atomic.Uint64
+	// lastActivity is the unix nano time of the last accept, read or write
+	lastActivity atomic.Int64
+}
+
+func (s *FwdStats) LastActivity() time.Time {
+	if nanos := s.lastActivity.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+func (s *FwdStats) touch() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+func (s *FwdStats) accepted() {
+	s.TotalConns.Add(1)
+	s.ActiveConns.Add(1)
+	s.touch()
+}
+
+func (s *FwdStats) closed() {
+	s.ActiveConns.Add(-1)
+}
+
+// wrap counts the traffic of an accepted connection.
+func (s *FwdStats) wrap(conn net.Conn) net.Conn {
+	return &countingConn{Conn: conn, stats: s}
+}
+
+// countDials wraps dial to count failed dials and update the status of the forward.
+func (f *FwdConn) countDials(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		conn, err := dial(ctx, network, addr)
+		f.dialed(start, err)
+		return conn, err
+	}
+}
+
+func (s *FwdStats) Proto(id string) *ctrlpb.FwdStats {
+	var lastActivity int64
+	if t := s.LastActivity(); !t.IsZero() {
+		lastActivity = t.Unix()
+	}
+	return &ctrlpb.FwdStats{
+		Id:           id,
+		BytesIn:      s.BytesIn.Load(),
+		BytesOut:     s.BytesOut.Load(),
+		ActiveConns:  s.ActiveConns.Load(),
+		TotalConns:   s.TotalConns.Load(),
+		DialFailures: s.DialFailures.Load(),
+		LastActivity: lastActivity,
+		Rejected:     s.Rejected.Load(),
+	}
+}
+
+type countingConn struct {
+	net.Conn
+	stats *FwdStats
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.stats.BytesIn.Add(uint64(n))
+		c.stats.touch()
+	}
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.stats.BytesOut.Add(uint64(n))
+		c.stats.touch()
+	}
+	return n, err
+}
+
+// Stats returns the stats of the forwards with the given ids, or of all forwards if no ids are given.
+// The returned ids are the serialized forward ids, ids that are not found are returned separately.
+func (t *Tunnel) Stats(ids ...string) ([]*ctrlpb.FwdStats, []string) {
+	t.connMu.RLock()
+	defer t.connMu.RUnlock()
+
+	var stats []*ctrlpb.FwdStats
+	var notFound []string
+	if len(ids) == 0 {
+		for id, c := range t.conns {
+			stats = append(stats, c.Stats.Proto(ser.Ser(t.Hash(), id)))
+		}
+		return stats, nil
+	}
+	for _, id := range ids {
+		if c, ok := t.conns[id]; ok {
+			stats = append(stats, c.Stats.Proto(ser.Ser(t.Hash(), id)))
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return stats, notFound
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"io"
+	"maps"
 	"net"
 	"os"
 	"strconv"
@@ -14,7 +15,12 @@ import (
 	"time"
 
 	"github.com/Phillezi/tunman/interrupt"
+	"github.com/Phillezi/tunman/log"
+	"github.com/Phillezi/tunman/pkg/acl"
+	"github.com/Phillezi/tunman/pkg/httpproxy"
+	"github.com/Phillezi/tunman/pkg/metrics"
 	"github.com/Phillezi/tunman/pkg/ser"
+	"github.com/Phillezi/tunman/pkg/socks"
 	sshutils "github.com/Phillezi/tunman/pkg/ssh"
 	ctrlpb "github.com/Phillezi/tunman/proto"
 	"github.com/Phillezi/tunman/utils"
@@ -26,6 +32,29 @@ import (
 type AddressPair struct {
 	LocalAddr  string
 	RemoteAddr string
+	// Kind decides which side listens, FWD_LOCAL listens locally
+	// and dials the remote (ssh -L), FWD_REMOTE does the reverse (ssh -R).
+	// FWD_DYNAMIC listens locally and serves SOCKS5 (ssh -D), FWD_HTTP listens locally
+	// and serves a HTTP proxy, RemoteAddr is unused for both.
+	Kind ctrlpb.FwdKind
+	// Socks holds the SOCKS5 options of FWD_DYNAMIC forwards.
+	Socks *ctrlpb.SocksOpts
+	// SocketMode is the file mode of the local socket when LocalAddr is a unix socket path.
+	SocketMode uint32
+	// Lazy forwards do not need the ssh connection until a connection is accepted,
+	// remote forwards can not be lazy since the ssh server does the listening.
+	Lazy bool
+	// ExpiresAt is when the forward is closed, the zero time if it does not expire.
+	ExpiresAt time.Time
+	// Allow and Deny are the CIDRs that clients of the forward may or may not connect from.
+	Allow []string
+	Deny  []string
+	// Owner is the tunnels file that manages the forward, empty if it was opened by hand.
+	Owner string
+	// Name is an optional unique name that can be used instead of the id.
+	Name string
+	// Labels are arbitrary key=value pairs used to select forwards.
+	Labels map[string]string
 
 	hash string
 }
@@ -39,9 +68,44 @@ func AddrPairToProto(addrs map[string]*FwdConn) map[string]*ctrlpb.AddrPair {
 }
 
 func (a *AddressPair) Proto() ctrlpb.AddrPair {
+	var expiresAt int64
+	if !a.ExpiresAt.IsZero() {
+		expiresAt = a.ExpiresAt.Unix()
+	}
 	return ctrlpb.AddrPair{
 		LocalAddr:  a.LocalAddr,
 		RemoteAddr: a.RemoteAddr,
+		Kind:       a.Kind,
+		Socks:      a.Socks,
+		SocketMode: a.SocketMode,
+		Lazy:       a.Lazy,
+		ExpiresAt:  expiresAt,
+		Allow:      a.Allow,
+		Deny:       a.Deny,
+		Owner:      a.Owner,
+		Name:       a.Name,
+		Labels:     a.Labels,
+	}
+}
+
+func AddrPairFromProto(a *ctrlpb.AddrPair) AddressPair {
+	var expiresAt time.Time
+	if a.GetExpiresAt() != 0 {
+		expiresAt = time.Unix(a.GetExpiresAt(), 0)
+	}
+	return AddressPair{
+		LocalAddr:  a.GetLocalAddr(),
+		RemoteAddr: a.GetRemoteAddr(),
+		Kind:       a.GetKind(),
+		Socks:      a.GetSocks(),
+		SocketMode: a.GetSocketMode(),
+		Lazy:       a.GetLazy(),
+		ExpiresAt:  expiresAt,
+		Allow:      a.Allow,
+		Deny:       a.Deny,
+		Owner:      a.Owner,
+		Name:       a.Name,
+		Labels:     a.Labels,
 	}
 }
 
@@ -51,17 +115,35 @@ func HashAddrPair(localAddr, remoteAddr string) string {
 	return strconv.FormatUint(h.Sum64(), 16) // 16 hex chars
 }
 
+// HashFwd hashes a forward of any kind, local forwards keep the plain HashAddrPair hash.
+// This only keeps the forward part of persisted ids, the tunnel part changed when ConnOpts.Hash
+// started to include the owner and forwards stored before that are rekeyed when they are restored.
+func HashFwd(kind ctrlpb.FwdKind, localAddr, remoteAddr string) string {
+	if kind == ctrlpb.FwdKind_FWD_LOCAL {
+		return HashAddrPair(localAddr, remoteAddr)
+	}
+	return HashAddrPair(kind.String()+"|"+localAddr, remoteAddr)
+}
+
 func (a *AddressPair) Hash() string {
 	if a.hash != "" {
 		return a.hash
 	}
-	a.hash = HashAddrPair(a.LocalAddr, a.RemoteAddr)
+	a.hash = HashFwd(a.Kind, a.LocalAddr, a.RemoteAddr)
 	return a.hash
 }
 
 type FwdConn struct {
-	AddrPair AddressPair
-	Cancel   context.CancelFunc
+	AddrPair  AddressPair
+	Cancel    context.CancelFunc
+	Stats     *FwdStats
+	Lifecycle *Lifecycle
+
+	acl *acl.ACL
+	// log tags the entries of the forward with its id
+	log *zap.Logger
+	// observeDial records the latency of successful dials
+	observeDial func(time.Duration)
 }
 
 type Tunnel struct {
@@ -72,16 +154,50 @@ type Tunnel struct {
 	once sync.Once
 	hash string
 
-	client *ssh.Client
-
-	conns  map[string]*FwdConn
+	client   *ssh.Client
+	clientMu sync.RWMutex
+	// up is closed while the client is connected
+	up chan struct{}
+
+	// baseCfg is the client config before any dial, used to redial
+	baseCfg ssh.ClientConfig
+	backoff backoff
+	// reconnectAttempts and lastErr are protected by clientMu
+	reconnectAttempts uint32
+	lastErr           error
+	// rtt is the last measured keepalive round trip time, protected by clientMu
+	rtt time.Duration
+	// closeReason is why the client was closed by us, protected by clientMu
+	closeReason error
+	// idle is set while the client is closed on purpose until a lazy forward needs it,
+	// connectedAt is when the client was connected, both are protected by clientMu
+	idle        bool
+	connectedAt time.Time
+	idleTimeout time.Duration
+	wakeMu      sync.Mutex
+
+	// publish receives the events of the tunnel, nil if they are not wanted
+	publish func(*ctrlpb.Event)
+	// prompt answers the challenges of the ssh servers, nil if there is no one to ask
+	prompt Prompter
+
+	conns map[string]*FwdConn
+	// labels are protected by connMu
+	labels map[string]string
 	connMu sync.RWMutex
 }
 
 type TunnelOpts struct {
 	ssh.ClientConfig
-	ctx    context.Context
-	cancel context.CancelFunc
+	ctx     context.Context
+	cancel  context.CancelFunc
+	backoff backoff
+
+	lazy        bool
+	idleTimeout time.Duration
+	publish     func(*ctrlpb.Event)
+	ownerUID    uint32
+	prompt      Prompter
 }
 
 type ConfigOption func(*TunnelOpts) error
@@ -94,6 +210,14 @@ func WithContext(ctx context.Context) ConfigOption {
 	}
 }
 
+// WithOwner returns an option to set the local user the tunnel belongs to.
+func WithOwner(uid uint32) ConfigOption {
+	return func(cfg *TunnelOpts) error {
+		cfg.ownerUID = uid
+		return nil
+	}
+}
+
 // WithPassword returns an option to authenticate with password.
 func WithPassword(password string) ConfigOption {
 	return func(cfg *TunnelOpts) error {
@@ -107,16 +231,39 @@ func WithPassword(password string) ConfigOption {
 }
 
 // WithPrivateKey returns an option to authenticate with a private key ([]byte form).
+// Encrypted keys are decrypted when dialing, with a passphrase asked for like for key files.
 func WithPrivateKey(key []byte) ConfigOption {
 	return func(cfg *TunnelOpts) error {
+		var auth ssh.AuthMethod
 		signer, err := ssh.ParsePrivateKey(key)
-		if err != nil {
+		var missing *ssh.PassphraseMissingError
+		switch {
+		case errors.As(err, &missing):
+			desc := "given by the client"
+			if missing.PublicKey != nil {
+				desc = ssh.FingerprintSHA256(missing.PublicKey)
+			}
+			auth = ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+				// the prompter may be set by a later option
+				var ask sshutils.PassphraseFunc
+				if cfg.prompt != nil {
+					ask = passphrasePrompt(cfg.prompt)
+				}
+				signer, err := sshutils.ParsePrivateKey(key, desc, cfg.ownerUID, ask)
+				if err != nil {
+					return nil, err
+				}
+				return []ssh.Signer{signer}, nil
+			})
+		case err != nil:
 			return err
+		default:
+			auth = ssh.PublicKeys(signer)
 		}
 		if len(cfg.Auth) == 0 {
-			cfg.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
+			cfg.Auth = []ssh.AuthMethod{auth}
 		} else {
-			cfg.Auth = utils.Prepend(cfg.Auth, ssh.PublicKeys(signer))
+			cfg.Auth = utils.Prepend(cfg.Auth, auth)
 		}
 		return nil
 	}
@@ -165,12 +312,30 @@ func WithProtoOpts(pw string, key []byte) []ConfigOption {
 }
 
 func (t *Tunnel) Proto() *ctrlpb.Tunnel {
+	t.connMu.RLock()
+	addrs := AddrPairToProto(t.conns)
+	tunLabels := maps.Clone(t.labels)
+	t.connMu.RUnlock()
+
+	t.clientMu.RLock()
+	defer t.clientMu.RUnlock()
+	var lastErr string
+	if t.lastErr != nil {
+		lastErr = t.lastErr.Error()
+	}
 	return &ctrlpb.Tunnel{
-		Id:          t.Hash(),
-		User:        t.uID.User,
-		Host:        t.uID.Host,
-		Port:        uint32(t.uID.Port),
-		AddressPair: AddrPairToProto(t.conns),
+		Id:                t.Hash(),
+		User:              t.uID.User,
+		Host:              t.uID.Host,
+		Port:              uint32(t.uID.Port),
+		AddressPair:       addrs,
+		Connected:         t.client != nil,
+		ReconnectAttempts: t.reconnectAttempts,
+		LastError:         lastErr,
+		RttNanos:          int64(t.rtt),
+		Idle:              t.idle,
+		Labels:            tunLabels,
+		OwnerUid:          t.uID.OwnerUID,
 	}
 }
 
@@ -180,6 +345,10 @@ type ConnOpts struct {
 	Port uint
 	addr string
 	Opts []ConfigOption
+	// Labels are added to the labels of the tunnel.
+	Labels map[string]string
+	// OwnerUID is the local user the tunnel belongs to, tunnels are never shared between users.
+	OwnerUID uint32
 }
 
 // New creates a new SSH tunnel to host (user@addr).
@@ -190,8 +359,9 @@ func New(user, host string, port uint, opts ...ConfigOption) (*Tunnel, error) {
 		ClientConfig: ssh.ClientConfig{
 			User: user,
 		},
-		ctx:    fallbackCtx,
-		cancel: fallbackCancel,
+		ctx:     fallbackCtx,
+		cancel:  fallbackCancel,
+		backoff: defaultBackoff,
 	}
 
 	for _, opt := range opts {
@@ -200,29 +370,49 @@ func New(user, host string, port uint, opts ...ConfigOption) (*Tunnel, error) {
 		}
 	}
 
-	client, err := sshutils.DialWithJumpChain(&sshutils.Target{
-		User: user,
-		Host: host,
-		Port: port,
-	}, &cfg.ClientConfig)
+	t := &Tunnel{
+		ctx:         cfg.ctx,
+		cancel:      cfg.cancel,
+		up:          make(chan struct{}),
+		baseCfg:     cfg.ClientConfig,
+		backoff:     cfg.backoff,
+		idleTimeout: cfg.idleTimeout,
+		publish:     cfg.publish,
+		prompt:      cfg.prompt,
+		uID: &ConnOpts{
+			User:     user,
+			Host:     host,
+			Port:     port,
+			addr:     "",
+			OwnerUID: cfg.ownerUID,
+		},
+		conns: make(map[string]*FwdConn),
+	}
+
+	if t.idleTimeout > 0 {
+		go t.idleLoop()
+	}
+
+	if cfg.lazy {
+		// dialed by the first connection that needs it
+		t.idle = true
+		return t, nil
+	}
+
+	client, err := t.dial()
 	if err != nil {
+		t.dialFailed(err)
+		cfg.cancel()
 		return nil, err
 	}
+	t.setClient(client)
+	t.emit(ctrlpb.EventType_EVENT_TUNNEL_DIALED, "", nil, nil)
 
-	return &Tunnel{
-		ctx:    cfg.ctx,
-		cancel: cfg.cancel,
-		client: client,
-		uID: &ConnOpts{
-			User: user,
-			Host: host,
-			Port: port,
-			addr: "",
-		},
-		conns: make(map[string]*FwdConn),
-	}, nil
+	return t, nil
 }
 
+// Hash identifies the tunnel by its user, resolved address and owner, persisted forwards with ids
+// from before the owner was part of it get new ids when the manager restores them.
 func (o *ConnOpts) Hash() string {
 	if o.addr == "" {
 		addr, err := sshutils.Resolve(&sshutils.Target{User: o.User, Host: o.Host, Port: o.Port})
@@ -235,10 +425,15 @@ func (o *ConnOpts) Hash() string {
 		}
 	}
 	h := fnv.New64a()
-	h.Write([]byte(o.User + o.addr))
+	fmt.Fprintf(h, "%s%s#%d", o.User, o.addr, o.OwnerUID)
 	return strconv.FormatUint(h.Sum64(), 16) // 16 hex chars
 }
 
+// OwnerUID returns the local user the tunnel belongs to.
+func (t *Tunnel) OwnerUID() uint32 {
+	return t.uID.OwnerUID
+}
+
 func (t *Tunnel) Hash() string {
 	t.once.Do(func() {
 		t.hash = t.uID.Hash()
@@ -246,6 +441,24 @@ func (t *Tunnel) Hash() string {
 	return t.hash
 }
 
+// logger returns a logger that tags its entries with the id of the tunnel.
+func (t *Tunnel) logger() *zap.Logger {
+	return zap.L().With(zap.String(log.TunnelKey, t.Hash()))
+}
+
+// AddLabels adds labels to the tunnel, replacing the values of existing keys.
+func (t *Tunnel) AddLabels(labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	t.connMu.Lock()
+	defer t.connMu.Unlock()
+	if t.labels == nil {
+		t.labels = make(map[string]string, len(labels))
+	}
+	maps.Copy(t.labels, labels)
+}
+
 func (t *Tunnel) Exists(id string) bool {
 	t.connMu.RLock()
 	defer t.connMu.RUnlock()
@@ -255,22 +468,27 @@ func (t *Tunnel) Exists(id string) bool {
 
 // Dial opens a connection through the tunnel to the target (e.g., localhost:3306).
 func (t *Tunnel) Dial(network, addr string) (net.Conn, error) {
-	if t.client == nil {
-		return nil, errors.New("ssh client not connected")
-	}
-	conn, err := t.client.Dial(network, addr)
-	if err != nil {
-		return nil, err
-	}
-	return conn, nil
+	return t.DialWCtx(t.ctx, network, addr)
 }
 
 // DialWCtx opens a connection through the tunnel to the target (e.g., localhost:3306).
+// An idle tunnel is connected first, if the tunnel is reconnecting it waits a while for the new client before giving up.
 func (t *Tunnel) DialWCtx(ctx context.Context, network, addr string) (net.Conn, error) {
-	if t.client == nil {
-		return nil, errors.New("ssh client not connected")
+	client := t.getClient()
+	if client == nil {
+		if err := t.wake(); err != nil {
+			return nil, fmt.Errorf("failed to connect idle tunnel: %w", err)
+		}
+		waitCtx, cancel := context.WithTimeout(ctx, reconnectDialWait)
+		defer cancel()
+		if err := t.waitConnected(waitCtx); err != nil {
+			return nil, errors.New("ssh client not connected")
+		}
+		if client = t.getClient(); client == nil {
+			return nil, errors.New("ssh client not connected")
+		}
 	}
-	conn, err := t.client.DialContext(ctx, network, addr)
+	conn, err := client.DialContext(ctx, network, addr)
 	if err != nil {
 		return nil, err
 	}
@@ -284,19 +502,21 @@ func (t *Tunnel) Close() error {
 	}
 
 	t.connMu.RLock()
-	for id, c := range t.conns {
+	for _, c := range t.conns {
 		// we need to call cancel to close all conns,
 		// since this cancel func closes the listener
 		// otherwise the go func is blocked listening
 		// and will not recv the ctx cancel : /
 		c.Cancel()
-		zap.L().Debug("cancelled fwd", zap.String("id", id))
+		c.log.Debug("cancelled fwd")
 	}
 	t.connMu.RUnlock()
 
-	if t.client != nil {
-		defer func() { t.client.Wait(); zap.L().Debug("ssh client closed"); t.client = nil }()
-		return t.client.Close()
+	t.emit(ctrlpb.EventType_EVENT_TUNNEL_CLOSED, "", nil, nil)
+
+	if client := t.takeClient(); client != nil {
+		defer func() { client.Wait(); t.logger().Debug("ssh client closed") }()
+		return client.Close()
 	}
 
 	return nil
@@ -308,6 +528,19 @@ func (t *Tunnel) FwdsCount() int {
 	return len(t.conns)
 }
 
+// Expired returns the ids of the forwards that have expired at now.
+func (t *Tunnel) Expired(now time.Time) []string {
+	t.connMu.RLock()
+	defer t.connMu.RUnlock()
+	var expired []string
+	for id, c := range t.conns {
+		if exp := c.AddrPair.ExpiresAt; !exp.IsZero() && !exp.After(now) {
+			expired = append(expired, id)
+		}
+	}
+	return expired
+}
+
 func (t *Tunnel) CloseFwd(ids ...string) ([]string, []string) {
 	var closed []string
 	var errors []string
@@ -318,7 +551,7 @@ func (t *Tunnel) CloseFwd(ids ...string) ([]string, []string) {
 			go func() {
 				if v.Cancel != nil {
 					v.Cancel()
-					zap.L().Info("closed forward", zap.String("id", id))
+					v.log.Info("closed forward")
 				}
 			}()
 			closed = append(closed, ser.Ser(t.Hash(), id))
@@ -329,30 +562,126 @@ func (t *Tunnel) CloseFwd(ids ...string) ([]string, []string) {
 	return closed, errors
 }
 
-// Forward listens on localAddr (e.g. "localhost:8080") and forwards all connections
-// to remoteAddr (e.g. "localhost:5432") through the SSH tunnel.
-func (t *Tunnel) Forward(ap AddressPair) error {
+// Forward starts serving the forward described by ap and blocks until it is closed.
+// Local forwards listen on LocalAddr (e.g. "localhost:8080") and forward all connections
+// to RemoteAddr (e.g. "localhost:5432") through the SSH tunnel, remote forwards
+// listen on RemoteAddr on the SSH server and forward connections to LocalAddr.
+// The status of the forward is tracked in lc, a new lifecycle is used if it is nil.
+func (t *Tunnel) Forward(ap AddressPair, lc *Lifecycle) error {
+	if lc == nil {
+		lc = NewLifecycle(ap.Hash())
+	}
+	err := t.forward(ap, lc)
+	if err != nil {
+		lc.Set(ctrlpb.FwdStatus_FWD_FAILED, err)
+	}
+	return err
+}
+
+func (t *Tunnel) forward(ap AddressPair, lc *Lifecycle) error {
+	if ap.Lazy && ap.Kind == ctrlpb.FwdKind_FWD_REMOTE {
+		return errors.New("remote forwards can not be lazy")
+	}
+	if !ap.Lazy {
+		if t.getClient() == nil {
+			lc.Set(ctrlpb.FwdStatus_FWD_DIALING, nil)
+		}
+		// the tunnel may be idle if all its other forwards are lazy
+		if err := t.wake(); err != nil {
+			return err
+		}
+	}
+
+	fwdACL, err := acl.New(ap.Allow, ap.Deny)
+	if err != nil {
+		return err
+	}
+
+	fwdID := ser.Ser(t.Hash(), ap.Hash())
+	metricLabel := metrics.ForwardLabel(ap.Name, fwdID)
+	defer metrics.ForgetForward(t.uID.Host, metricLabel, fwdID)
+	fwd := &FwdConn{
+		AddrPair:    ap,
+		Stats:       &FwdStats{},
+		Lifecycle:   lc,
+		acl:         fwdACL,
+		log:         t.logger().With(zap.String(log.FwdKey, fwdID)),
+		observeDial: metrics.DialObserver(t.uID.Host, metricLabel, fwdID),
+	}
+	switch ap.Kind {
+	case ctrlpb.FwdKind_FWD_REMOTE:
+		return t.serve(fwd, t.listenRemote, t.handleReverseConn)
+	case ctrlpb.FwdKind_FWD_DYNAMIC:
+		return t.serve(fwd, t.listenLocal, t.handleDynamicConn)
+	case ctrlpb.FwdKind_FWD_HTTP:
+		proxy := httpproxy.New(fwd.countDials(t.DialWCtx))
+		defer proxy.Close()
+		return t.serve(fwd, t.listenLocal, func(ctx context.Context, localConn net.Conn, fwd *FwdConn) {
+			t.handleHTTPProxyConn(ctx, localConn, fwd, proxy)
+		})
+	default:
+		return t.serve(fwd, t.listenLocal, t.handleForwardConn)
+	}
+}
+
+const socksHandshakeTimeout = 30 * time.Second
+
+type connHandler func(ctx context.Context, conn net.Conn, fwd *FwdConn)
+
+func (t *Tunnel) listenLocal(ap AddressPair) (net.Listener, error) {
+	if err := CheckLocalAccess(t.OwnerUID(), ap); err != nil {
+		return nil, err
+	}
+	if network(ap.LocalAddr) == "unix" {
+		return listenUnix(ap.LocalAddr, ap.SocketMode, t.OwnerUID())
+	}
+	return net.Listen("tcp", ap.LocalAddr)
+}
+
+// listenRemote listens on the SSH server, unix socket paths are
+// forwarded using the streamlocal-forward@openssh.com request.
+func (t *Tunnel) listenRemote(ap AddressPair) (net.Listener, error) {
+	client := t.getClient()
+	if client == nil {
+		return nil, errors.New("ssh client not connected")
+	}
+	if network(ap.RemoteAddr) == "unix" {
+		return client.ListenUnix(ap.RemoteAddr)
+	}
+	return client.Listen("tcp", ap.RemoteAddr)
+}
+
+func (t *Tunnel) serve(fwd *FwdConn, listen func(AddressPair) (net.Listener, error), handle connHandler) error {
+	ap := fwd.AddrPair
 	id := ap.Hash()
-	defer zap.L().Debug("Forward exited", zap.String("id", id))
+	defer fwd.log.Debug("Forward exited")
 	var once sync.Once
 
-	listener, err := net.Listen("tcp", ap.LocalAddr)
+	listener, err := listen(ap)
 	if err != nil {
 		return fmt.Errorf("listen error: %w", err)
 	}
+	// the listener is replaced when a remote forward is listened on again after a reconnect
+	var listenerMu sync.Mutex
+	closeListener := func() {
+		listenerMu.Lock()
+		defer listenerMu.Unlock()
+		listener.Close()
+	}
 	ctx, cancel := context.WithCancel(t.ctx)
 	defer once.Do(func() {
-		listener.Close()
+		closeListener()
 		cancel()
 	})
 
-	t.connMu.Lock()
-	t.conns[id] = &FwdConn{AddrPair: ap, Cancel: func() {
+	fwd.Cancel = func() {
 		once.Do(func() {
-			listener.Close()
+			closeListener()
 			cancel()
 		})
-	}}
+	}
+	t.connMu.Lock()
+	t.conns[id] = fwd
 	t.connMu.Unlock()
 	defer func() {
 		go func() {
@@ -363,27 +692,58 @@ func (t *Tunnel) Forward(ap AddressPair) error {
 				// if our app wants to exit it is ok, since this will just wait for a lock and remove a conn from itself
 				// but if it is a exit it does not matter
 				if interrupt.GetInstance().Context().Err() == nil {
-					zap.L().Warn("timed out when trying to remove fwd on exit from fwds in tunnel", zap.String("id", id))
+					fwd.log.Warn("timed out when trying to remove fwd on exit from fwds in tunnel")
 				}
 			default:
 				t.connMu.Lock()
 				defer t.connMu.Unlock()
 				delete(t.conns, id)
-				zap.L().Debug("succesfully removed fwd from fwds in tunnel", zap.String("id", id))
+				fwd.log.Debug("succesfully removed fwd from fwds in tunnel")
 			}
 		}()
 	}()
 
-	zap.L().Info("Forwarding", zap.String("id", id), zap.String("local", ap.LocalAddr), zap.String("remote", ap.RemoteAddr))
+	fwd.Lifecycle.Set(t.listening(), nil)
+	t.emit(ctrlpb.EventType_EVENT_FWD_LISTENING, id, nil, nil)
+	fwd.log.Info("Forwarding", zap.String("kind", ap.Kind.String()), zap.String("local", ap.LocalAddr), zap.String("remote", ap.RemoteAddr))
 
 	for {
 		select {
 		case <-ctx.Done():
-			zap.L().Info("context cancelled, exiting", zap.String("id", id))
+			fwd.log.Info("context cancelled, exiting")
 			return nil
 		default:
-			localConn, err := listener.Accept()
+			listenerMu.Lock()
+			l := listener
+			listenerMu.Unlock()
+			conn, err := l.Accept()
 			if err != nil {
+				if ctx.Err() != nil {
+					// the listener was closed by us
+					return nil
+				}
+				if ap.Kind == ctrlpb.FwdKind_FWD_REMOTE {
+					// remote listeners die with the ssh client, listen again once reconnected
+					fwd.log.Warn("remote listener closed, waiting for reconnect", zap.Error(err))
+					fwd.Lifecycle.Set(ctrlpb.FwdStatus_FWD_RECONNECTING, err)
+					newListener, err := t.relisten(ctx, fwd, listen)
+					if err != nil {
+						if ctx.Err() != nil {
+							return nil
+						}
+						return fmt.Errorf("listen error: %w", err)
+					}
+					listenerMu.Lock()
+					listener = newListener
+					listenerMu.Unlock()
+					if ctx.Err() != nil {
+						newListener.Close()
+						return nil
+					}
+					fwd.Lifecycle.Set(ctrlpb.FwdStatus_FWD_LISTENING, nil)
+					t.emit(ctrlpb.EventType_EVENT_FWD_LISTENING, id, nil, nil)
+					continue
+				}
 				if opErr, ok := err.(*net.OpError); ok {
 					// Handle "use of closed network connection"
 					// the actual error is poll.errNetClosing, but it is private so i cant check if it is that
@@ -394,29 +754,114 @@ func (t *Tunnel) Forward(ap AddressPair) error {
 				return fmt.Errorf("accept error: %w", err)
 			}
 
-			go t.handleForwardConn(ctx, localConn, ap.RemoteAddr)
+			if !fwd.acl.Allowed(conn.RemoteAddr()) {
+				fwd.Stats.Rejected.Add(1)
+				fwd.log.Warn("rejected connection by acl", zap.Stringer("client", conn.RemoteAddr()))
+				conn.Close()
+				continue
+			}
+
+			fwd.Stats.accepted()
+			peer := conn.RemoteAddr()
+			t.emit(ctrlpb.EventType_EVENT_CONN_ACCEPTED, id, peer, nil)
+			go func() {
+				defer t.emit(ctrlpb.EventType_EVENT_CONN_CLOSED, id, peer, nil)
+				defer fwd.Stats.closed()
+				handle(ctx, fwd.Stats.wrap(conn), fwd)
+			}()
 		}
 	}
 }
 
-func (t *Tunnel) handleForwardConn(ctx context.Context, localConn net.Conn, remoteAddr string) {
+// handleForwardConn dials the remote address through the tunnel for a locally accepted connection.
+func (t *Tunnel) handleForwardConn(ctx context.Context, localConn net.Conn, fwd *FwdConn) {
 	//defer zap.L().Debug("handleForwardConn exited")
 	defer localConn.Close()
 
-	remoteConn, err := t.DialWCtx(ctx, "tcp", remoteAddr)
+	// unix socket paths are dialed using direct-streamlocal@openssh.com channels
+	remoteAddr := fwd.AddrPair.RemoteAddr
+	start := time.Now()
+	remoteConn, err := t.DialWCtx(ctx, network(remoteAddr), remoteAddr)
+	fwd.dialed(start, err)
+	if err != nil {
+		fwd.log.Error("SSH dial failed", zap.String("remoteAddr", remoteAddr), zap.Error(err))
+		return
+	}
+	defer remoteConn.Close()
+
+	pipe(ctx, localConn, remoteConn)
+}
+
+// handleReverseConn dials the local address for a connection accepted on the SSH server.
+func (t *Tunnel) handleReverseConn(ctx context.Context, remoteConn net.Conn, fwd *FwdConn) {
+	defer remoteConn.Close()
+
+	var d net.Dialer
+	localAddr := fwd.AddrPair.LocalAddr
+	start := time.Now()
+	// checked on every dial, the socket may have been replaced since the forward was opened
+	err := CheckLocalAccess(t.OwnerUID(), fwd.AddrPair)
+	var localConn net.Conn
+	if err == nil {
+		localConn, err = d.DialContext(ctx, network(localAddr), localAddr)
+	}
+	fwd.dialed(start, err)
+	if err != nil {
+		fwd.log.Error("local dial failed", zap.String("localAddr", localAddr), zap.Error(err))
+		return
+	}
+	defer localConn.Close()
+
+	pipe(ctx, remoteConn, localConn)
+}
+
+// handleDynamicConn serves SOCKS5 on a locally accepted connection and dials the requested target through the tunnel.
+func (t *Tunnel) handleDynamicConn(ctx context.Context, localConn net.Conn, fwd *FwdConn) {
+	defer localConn.Close()
+
+	opts := fwd.AddrPair.Socks
+	srv := socks.Server{
+		Dial:         fwd.countDials(t.DialWCtx),
+		Username:     opts.GetUser(),
+		Password:     opts.GetPassword(),
+		ResolveLocal: opts.GetResolveLocal(),
+	}
+
+	localConn.SetDeadline(time.Now().Add(socksHandshakeTimeout))
+	remoteConn, err := srv.Handshake(ctx, localConn)
 	if err != nil {
-		zap.L().Error("SSH dial failed", zap.Error(err))
+		fwd.log.Error("socks handshake failed", zap.Stringer("client", localConn.RemoteAddr()), zap.Error(err))
 		return
 	}
 	defer remoteConn.Close()
+	localConn.SetDeadline(time.Time{})
+
+	pipe(ctx, localConn, remoteConn)
+}
+
+// handleHTTPProxyConn serves HTTP proxy requests on a locally accepted connection.
+func (t *Tunnel) handleHTTPProxyConn(ctx context.Context, localConn net.Conn, fwd *FwdConn, proxy *httpproxy.Proxy) {
+	defer localConn.Close()
 
+	if err := proxy.ServeConn(ctx, localConn); err != nil && ctx.Err() == nil {
+		fwd.log.Error("http proxy failed", zap.Stringer("client", localConn.RemoteAddr()), zap.Error(err))
+	}
+}
+
+// pipe copies data between a and b until either side is done or ctx is cancelled.
+func pipe(ctx context.Context, a, b net.Conn) {
+	done := make(chan struct{})
+	defer close(done)
 	go func() {
-		<-ctx.Done()
-		zap.L().Debug("handleForwardConn recv ctx cancelled")
-		localConn.Close()
-		remoteConn.Close()
+		select {
+		case <-ctx.Done():
+			zap.L().Debug("pipe recv ctx cancelled")
+			a.Close()
+			b.Close()
+		case <-done:
+		}
 	}()
 
-	go io.Copy(remoteConn, localConn)
-	io.Copy(localConn, remoteConn)
+	go io.Copy(b, a)
+	io.Copy(a, b)
 }
@@ -0,0 +1,68 @@
+package tunnel
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/Phillezi/tunman/internal/defaults"
+	"github.com/Phillezi/tunman/utils"
+	"go.uber.org/zap"
+)
+
+// network returns the network to use for addr, "unix" for socket paths and "tcp" otherwise.
+func network(addr string) string {
+	if utils.IsSocketPath(addr) {
+		return "unix"
+	}
+	return "tcp"
+}
+
+// listenUnix listens on a local unix socket, removing a stale socket left at path
+// and setting the permissions of the socket file to mode and its owner to owner.
+// The socket file is removed again when the returned listener is closed.
+func listenUnix(path string, mode uint32, owner uint32) (net.Listener, error) {
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, os.FileMode(utils.Or(mode, defaults.DefaultSocketMode))); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	if !trusted(owner) {
+		if err := os.Lchown(path, int(owner), -1); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to set socket owner: %w", err)
+		}
+	}
+
+	return listener, nil
+}
+
+// removeStaleSocket removes the socket at path if nothing is listening on it.
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is already in use", path)
+	}
+
+	zap.L().Debug("removing stale socket", zap.String("path", path))
+	return os.Remove(path)
+}
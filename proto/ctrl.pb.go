@@ -21,17 +21,330 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
-type AddrPair struct {
+type FwdKind int32
+
+const (
+	FwdKind_FWD_LOCAL   FwdKind = 0
+	FwdKind_FWD_REMOTE  FwdKind = 1
+	FwdKind_FWD_DYNAMIC FwdKind = 2
+	FwdKind_FWD_HTTP    FwdKind = 3
+)
+
+// Enum value maps for FwdKind.
+var (
+	FwdKind_name = map[int32]string{
+		0: "FWD_LOCAL",
+		1: "FWD_REMOTE",
+		2: "FWD_DYNAMIC",
+		3: "FWD_HTTP",
+	}
+	FwdKind_value = map[string]int32{
+		"FWD_LOCAL":   0,
+		"FWD_REMOTE":  1,
+		"FWD_DYNAMIC": 2,
+		"FWD_HTTP":    3,
+	}
+)
+
+func (x FwdKind) Enum() *FwdKind {
+	p := new(FwdKind)
+	*p = x
+	return p
+}
+
+func (x FwdKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FwdKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_ctrl_proto_enumTypes[0].Descriptor()
+}
+
+func (FwdKind) Type() protoreflect.EnumType {
+	return &file_ctrl_proto_enumTypes[0]
+}
+
+func (x FwdKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FwdKind.Descriptor instead.
+func (FwdKind) EnumDescriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{0}
+}
+
+// FwdStatus is the lifecycle state of a forward.
+type FwdStatus int32
+
+const (
+	// FWD_PENDING forwards have not been started yet
+	FwdStatus_FWD_PENDING FwdStatus = 0
+	// FWD_DIALING forwards wait for the ssh connection before they can listen or serve
+	FwdStatus_FWD_DIALING   FwdStatus = 1
+	FwdStatus_FWD_LISTENING FwdStatus = 2
+	// FWD_DEGRADED forwards listen but failed to dial their target
+	FwdStatus_FWD_DEGRADED FwdStatus = 3
+	// FWD_RECONNECTING forwards listen but the ssh connection is lost
+	FwdStatus_FWD_RECONNECTING FwdStatus = 4
+	// FWD_FAILED forwards are not running, see last_error
+	FwdStatus_FWD_FAILED FwdStatus = 5
+	// FWD_PAUSED forwards listen but their idle ssh connection is closed until needed
+	FwdStatus_FWD_PAUSED FwdStatus = 6
+)
+
+// Enum value maps for FwdStatus.
+var (
+	FwdStatus_name = map[int32]string{
+		0: "FWD_PENDING",
+		1: "FWD_DIALING",
+		2: "FWD_LISTENING",
+		3: "FWD_DEGRADED",
+		4: "FWD_RECONNECTING",
+		5: "FWD_FAILED",
+		6: "FWD_PAUSED",
+	}
+	FwdStatus_value = map[string]int32{
+		"FWD_PENDING":      0,
+		"FWD_DIALING":      1,
+		"FWD_LISTENING":    2,
+		"FWD_DEGRADED":     3,
+		"FWD_RECONNECTING": 4,
+		"FWD_FAILED":       5,
+		"FWD_PAUSED":       6,
+	}
+)
+
+func (x FwdStatus) Enum() *FwdStatus {
+	p := new(FwdStatus)
+	*p = x
+	return p
+}
+
+func (x FwdStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FwdStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_ctrl_proto_enumTypes[1].Descriptor()
+}
+
+func (FwdStatus) Type() protoreflect.EnumType {
+	return &file_ctrl_proto_enumTypes[1]
+}
+
+func (x FwdStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FwdStatus.Descriptor instead.
+func (FwdStatus) EnumDescriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{1}
+}
+
+type ApplyAction int32
+
+const (
+	ApplyAction_APPLY_UNCHANGED ApplyAction = 0
+	ApplyAction_APPLY_OPEN      ApplyAction = 1
+	ApplyAction_APPLY_CLOSE     ApplyAction = 2
+	ApplyAction_APPLY_REPLACE   ApplyAction = 3
+)
+
+// Enum value maps for ApplyAction.
+var (
+	ApplyAction_name = map[int32]string{
+		0: "APPLY_UNCHANGED",
+		1: "APPLY_OPEN",
+		2: "APPLY_CLOSE",
+		3: "APPLY_REPLACE",
+	}
+	ApplyAction_value = map[string]int32{
+		"APPLY_UNCHANGED": 0,
+		"APPLY_OPEN":      1,
+		"APPLY_CLOSE":     2,
+		"APPLY_REPLACE":   3,
+	}
+)
+
+func (x ApplyAction) Enum() *ApplyAction {
+	p := new(ApplyAction)
+	*p = x
+	return p
+}
+
+func (x ApplyAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ApplyAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_ctrl_proto_enumTypes[2].Descriptor()
+}
+
+func (ApplyAction) Type() protoreflect.EnumType {
+	return &file_ctrl_proto_enumTypes[2]
+}
+
+func (x ApplyAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ApplyAction.Descriptor instead.
+func (ApplyAction) EnumDescriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{2}
+}
+
+type EventType int32
+
+const (
+	EventType_EVENT_UNKNOWN            EventType = 0
+	EventType_EVENT_TUNNEL_DIALED      EventType = 1
+	EventType_EVENT_AUTH_FAILED        EventType = 2
+	EventType_EVENT_FWD_LISTENING      EventType = 3
+	EventType_EVENT_CONN_ACCEPTED      EventType = 4
+	EventType_EVENT_CONN_CLOSED        EventType = 5
+	EventType_EVENT_TUNNEL_LOST        EventType = 6
+	EventType_EVENT_TUNNEL_RECONNECTED EventType = 7
+	EventType_EVENT_TUNNEL_CLOSED      EventType = 8
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_UNKNOWN",
+		1: "EVENT_TUNNEL_DIALED",
+		2: "EVENT_AUTH_FAILED",
+		3: "EVENT_FWD_LISTENING",
+		4: "EVENT_CONN_ACCEPTED",
+		5: "EVENT_CONN_CLOSED",
+		6: "EVENT_TUNNEL_LOST",
+		7: "EVENT_TUNNEL_RECONNECTED",
+		8: "EVENT_TUNNEL_CLOSED",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_UNKNOWN":            0,
+		"EVENT_TUNNEL_DIALED":      1,
+		"EVENT_AUTH_FAILED":        2,
+		"EVENT_FWD_LISTENING":      3,
+		"EVENT_CONN_ACCEPTED":      4,
+		"EVENT_CONN_CLOSED":        5,
+		"EVENT_TUNNEL_LOST":        6,
+		"EVENT_TUNNEL_RECONNECTED": 7,
+		"EVENT_TUNNEL_CLOSED":      8,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_ctrl_proto_enumTypes[3].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_ctrl_proto_enumTypes[3]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{3}
+}
+
+type SocksOpts struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	LocalAddr     string                 `protobuf:"bytes,1,opt,name=localAddr,proto3" json:"localAddr,omitempty"`
-	RemoteAddr    string                 `protobuf:"bytes,2,opt,name=remoteAddr,proto3" json:"remoteAddr,omitempty"`
+	User          string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	ResolveLocal  bool                   `protobuf:"varint,3,opt,name=resolve_local,json=resolveLocal,proto3" json:"resolve_local,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SocksOpts) Reset() {
+	*x = SocksOpts{}
+	mi := &file_ctrl_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SocksOpts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SocksOpts) ProtoMessage() {}
+
+func (x *SocksOpts) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SocksOpts.ProtoReflect.Descriptor instead.
+func (*SocksOpts) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SocksOpts) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *SocksOpts) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *SocksOpts) GetResolveLocal() bool {
+	if x != nil {
+		return x.ResolveLocal
+	}
+	return false
+}
+
+type AddrPair struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	LocalAddr  string                 `protobuf:"bytes,1,opt,name=localAddr,proto3" json:"localAddr,omitempty"`
+	RemoteAddr string                 `protobuf:"bytes,2,opt,name=remoteAddr,proto3" json:"remoteAddr,omitempty"`
+	Kind       FwdKind                `protobuf:"varint,3,opt,name=kind,proto3,enum=ctrl.FwdKind" json:"kind,omitempty"`
+	Socks      *SocksOpts             `protobuf:"bytes,4,opt,name=socks,proto3" json:"socks,omitempty"`
+	SocketMode uint32                 `protobuf:"varint,5,opt,name=socket_mode,json=socketMode,proto3" json:"socket_mode,omitempty"`
+	Lazy       bool                   `protobuf:"varint,6,opt,name=lazy,proto3" json:"lazy,omitempty"`
+	// expires_at is the unix time the forward is closed at, 0 if it does not expire
+	ExpiresAt int64 `protobuf:"varint,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// allow and deny are the CIDRs clients may or may not connect from
+	Allow []string `protobuf:"bytes,8,rep,name=allow,proto3" json:"allow,omitempty"`
+	Deny  []string `protobuf:"bytes,9,rep,name=deny,proto3" json:"deny,omitempty"`
+	// owner is set on forwards managed by a tunnels file
+	Owner string `protobuf:"bytes,10,opt,name=owner,proto3" json:"owner,omitempty"`
+	// name is a unique human readable name of the forward
+	Name          string            `protobuf:"bytes,11,opt,name=name,proto3" json:"name,omitempty"`
+	Labels        map[string]string `protobuf:"bytes,12,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *AddrPair) Reset() {
 	*x = AddrPair{}
-	mi := &file_ctrl_proto_msgTypes[0]
+	mi := &file_ctrl_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -43,7 +356,7 @@ func (x *AddrPair) String() string {
 func (*AddrPair) ProtoMessage() {}
 
 func (x *AddrPair) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[0]
+	mi := &file_ctrl_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -56,7 +369,7 @@ func (x *AddrPair) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AddrPair.ProtoReflect.Descriptor instead.
 func (*AddrPair) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{0}
+	return file_ctrl_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *AddrPair) GetLocalAddr() string {
@@ -73,22 +386,100 @@ func (x *AddrPair) GetRemoteAddr() string {
 	return ""
 }
 
+func (x *AddrPair) GetKind() FwdKind {
+	if x != nil {
+		return x.Kind
+	}
+	return FwdKind_FWD_LOCAL
+}
+
+func (x *AddrPair) GetSocks() *SocksOpts {
+	if x != nil {
+		return x.Socks
+	}
+	return nil
+}
+
+func (x *AddrPair) GetSocketMode() uint32 {
+	if x != nil {
+		return x.SocketMode
+	}
+	return 0
+}
+
+func (x *AddrPair) GetLazy() bool {
+	if x != nil {
+		return x.Lazy
+	}
+	return false
+}
+
+func (x *AddrPair) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *AddrPair) GetAllow() []string {
+	if x != nil {
+		return x.Allow
+	}
+	return nil
+}
+
+func (x *AddrPair) GetDeny() []string {
+	if x != nil {
+		return x.Deny
+	}
+	return nil
+}
+
+func (x *AddrPair) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *AddrPair) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AddrPair) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
 type Tunnel struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
-	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
-	Port          uint32                 `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
-	AddressPair   map[string]*AddrPair   `protobuf:"bytes,5,rep,name=address_pair,json=addressPair,proto3" json:"address_pair,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Pw            string                 `protobuf:"bytes,6,opt,name=pw,proto3" json:"pw,omitempty"`
-	Privkey       []byte                 `protobuf:"bytes,7,opt,name=privkey,proto3" json:"privkey,omitempty"`
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	User              string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Host              string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Port              uint32                 `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	AddressPair       map[string]*AddrPair   `protobuf:"bytes,5,rep,name=address_pair,json=addressPair,proto3" json:"address_pair,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Pw                string                 `protobuf:"bytes,6,opt,name=pw,proto3" json:"pw,omitempty"`
+	Privkey           []byte                 `protobuf:"bytes,7,opt,name=privkey,proto3" json:"privkey,omitempty"`
+	Connected         bool                   `protobuf:"varint,8,opt,name=connected,proto3" json:"connected,omitempty"`
+	ReconnectAttempts uint32                 `protobuf:"varint,9,opt,name=reconnect_attempts,json=reconnectAttempts,proto3" json:"reconnect_attempts,omitempty"`
+	LastError         string                 `protobuf:"bytes,10,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	RttNanos          int64                  `protobuf:"varint,11,opt,name=rtt_nanos,json=rttNanos,proto3" json:"rtt_nanos,omitempty"`
+	Idle              bool                   `protobuf:"varint,12,opt,name=idle,proto3" json:"idle,omitempty"`
+	Labels            map[string]string      `protobuf:"bytes,13,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// owner_uid is the local user the tunnel belongs to
+	OwnerUid      uint32 `protobuf:"varint,14,opt,name=owner_uid,json=ownerUid,proto3" json:"owner_uid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Tunnel) Reset() {
 	*x = Tunnel{}
-	mi := &file_ctrl_proto_msgTypes[1]
+	mi := &file_ctrl_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -100,7 +491,7 @@ func (x *Tunnel) String() string {
 func (*Tunnel) ProtoMessage() {}
 
 func (x *Tunnel) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[1]
+	mi := &file_ctrl_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -113,7 +504,7 @@ func (x *Tunnel) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tunnel.ProtoReflect.Descriptor instead.
 func (*Tunnel) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{1}
+	return file_ctrl_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Tunnel) GetId() string {
@@ -160,35 +551,1346 @@ func (x *Tunnel) GetPw() string {
 
 func (x *Tunnel) GetPrivkey() []byte {
 	if x != nil {
-		return x.Privkey
+		return x.Privkey
+	}
+	return nil
+}
+
+func (x *Tunnel) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *Tunnel) GetReconnectAttempts() uint32 {
+	if x != nil {
+		return x.ReconnectAttempts
+	}
+	return 0
+}
+
+func (x *Tunnel) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Tunnel) GetRttNanos() int64 {
+	if x != nil {
+		return x.RttNanos
+	}
+	return 0
+}
+
+func (x *Tunnel) GetIdle() bool {
+	if x != nil {
+		return x.Idle
+	}
+	return false
+}
+
+func (x *Tunnel) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Tunnel) GetOwnerUid() uint32 {
+	if x != nil {
+		return x.OwnerUid
+	}
+	return 0
+}
+
+type FwdTransition struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Status FwdStatus              `protobuf:"varint,1,opt,name=status,proto3,enum=ctrl.FwdStatus" json:"status,omitempty"`
+	// at is the unix time of the transition
+	At            int64  `protobuf:"varint,2,opt,name=at,proto3" json:"at,omitempty"`
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FwdTransition) Reset() {
+	*x = FwdTransition{}
+	mi := &file_ctrl_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FwdTransition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FwdTransition) ProtoMessage() {}
+
+func (x *FwdTransition) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FwdTransition.ProtoReflect.Descriptor instead.
+func (*FwdTransition) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FwdTransition) GetStatus() FwdStatus {
+	if x != nil {
+		return x.Status
+	}
+	return FwdStatus_FWD_PENDING
+}
+
+func (x *FwdTransition) GetAt() int64 {
+	if x != nil {
+		return x.At
+	}
+	return 0
+}
+
+func (x *FwdTransition) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Fwd struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Parent        *Tunnel                `protobuf:"bytes,2,opt,name=parent,proto3" json:"parent,omitempty"`
+	Addrs         *AddrPair              `protobuf:"bytes,3,opt,name=addrs,proto3" json:"addrs,omitempty"`
+	Status        FwdStatus              `protobuf:"varint,4,opt,name=status,proto3,enum=ctrl.FwdStatus" json:"status,omitempty"`
+	LastError     string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Transitions   []*FwdTransition       `protobuf:"bytes,6,rep,name=transitions,proto3" json:"transitions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fwd) Reset() {
+	*x = Fwd{}
+	mi := &file_ctrl_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fwd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fwd) ProtoMessage() {}
+
+func (x *Fwd) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fwd.ProtoReflect.Descriptor instead.
+func (*Fwd) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Fwd) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Fwd) GetParent() *Tunnel {
+	if x != nil {
+		return x.Parent
+	}
+	return nil
+}
+
+func (x *Fwd) GetAddrs() *AddrPair {
+	if x != nil {
+		return x.Addrs
+	}
+	return nil
+}
+
+func (x *Fwd) GetStatus() FwdStatus {
+	if x != nil {
+		return x.Status
+	}
+	return FwdStatus_FWD_PENDING
+}
+
+func (x *Fwd) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Fwd) GetTransitions() []*FwdTransition {
+	if x != nil {
+		return x.Transitions
+	}
+	return nil
+}
+
+type FwdState struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Port          uint32                 `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	Addrs         *AddrPair              `protobuf:"bytes,5,opt,name=addrs,proto3" json:"addrs,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Name          string                 `protobuf:"bytes,7,opt,name=name,proto3" json:"name,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,8,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TunnelLabels  map[string]string      `protobuf:"bytes,9,rep,name=tunnel_labels,json=tunnelLabels,proto3" json:"tunnel_labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	OwnerUid      uint32                 `protobuf:"varint,10,opt,name=owner_uid,json=ownerUid,proto3" json:"owner_uid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FwdState) Reset() {
+	*x = FwdState{}
+	mi := &file_ctrl_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FwdState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FwdState) ProtoMessage() {}
+
+func (x *FwdState) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FwdState.ProtoReflect.Descriptor instead.
+func (*FwdState) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FwdState) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FwdState) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *FwdState) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *FwdState) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *FwdState) GetAddrs() *AddrPair {
+	if x != nil {
+		return x.Addrs
+	}
+	return nil
+}
+
+func (x *FwdState) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *FwdState) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FwdState) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *FwdState) GetTunnelLabels() map[string]string {
+	if x != nil {
+		return x.TunnelLabels
+	}
+	return nil
+}
+
+func (x *FwdState) GetOwnerUid() uint32 {
+	if x != nil {
+		return x.OwnerUid
+	}
+	return 0
+}
+
+type PsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// selector filters forwards by their and their tunnels labels, e.g. env=prod,project!=billing
+	Selector string `protobuf:"bytes,1,opt,name=selector,proto3" json:"selector,omitempty"`
+	// all_users lists the forwards of every user instead of those of the caller, only allowed for admins
+	AllUsers      bool `protobuf:"varint,2,opt,name=all_users,json=allUsers,proto3" json:"all_users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PsRequest) Reset() {
+	*x = PsRequest{}
+	mi := &file_ctrl_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PsRequest) ProtoMessage() {}
+
+func (x *PsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PsRequest.ProtoReflect.Descriptor instead.
+func (*PsRequest) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PsRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+func (x *PsRequest) GetAllUsers() bool {
+	if x != nil {
+		return x.AllUsers
+	}
+	return false
+}
+
+type PsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fwds          []*Fwd                 `protobuf:"bytes,1,rep,name=fwds,proto3" json:"fwds,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PsResponse) Reset() {
+	*x = PsResponse{}
+	mi := &file_ctrl_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PsResponse) ProtoMessage() {}
+
+func (x *PsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PsResponse.ProtoReflect.Descriptor instead.
+func (*PsResponse) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PsResponse) GetFwds() []*Fwd {
+	if x != nil {
+		return x.Fwds
+	}
+	return nil
+}
+
+func (x *PsResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type OpenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tunnels       []*Tunnel              `protobuf:"bytes,1,rep,name=tunnels,proto3" json:"tunnels,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpenRequest) Reset() {
+	*x = OpenRequest{}
+	mi := &file_ctrl_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenRequest) ProtoMessage() {}
+
+func (x *OpenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenRequest.ProtoReflect.Descriptor instead.
+func (*OpenRequest) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *OpenRequest) GetTunnels() []*Tunnel {
+	if x != nil {
+		return x.Tunnels
+	}
+	return nil
+}
+
+func (x *OpenRequest) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type OpenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OpenedIds     []string               `protobuf:"bytes,1,rep,name=opened_ids,json=openedIds,proto3" json:"opened_ids,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpenResponse) Reset() {
+	*x = OpenResponse{}
+	mi := &file_ctrl_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenResponse) ProtoMessage() {}
+
+func (x *OpenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenResponse.ProtoReflect.Descriptor instead.
+func (*OpenResponse) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OpenResponse) GetOpenedIds() []string {
+	if x != nil {
+		return x.OpenedIds
+	}
+	return nil
+}
+
+func (x *OpenResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+// Prompt asks the user opening a tunnel to answer the questions of its ssh server,
+// for keyboard-interactive challenges like one-time passwords and for passwords.
+type Prompt struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// id is sent back with the answers
+	Id          uint32   `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	User        string   `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Host        string   `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Name        string   `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Instruction string   `protobuf:"bytes,5,opt,name=instruction,proto3" json:"instruction,omitempty"`
+	Questions   []string `protobuf:"bytes,6,rep,name=questions,proto3" json:"questions,omitempty"`
+	// echos reports for each question if its answer may be shown while it is typed
+	Echos         []bool `protobuf:"varint,7,rep,packed,name=echos,proto3" json:"echos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prompt) Reset() {
+	*x = Prompt{}
+	mi := &file_ctrl_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prompt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prompt) ProtoMessage() {}
+
+func (x *Prompt) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prompt.ProtoReflect.Descriptor instead.
+func (*Prompt) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Prompt) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Prompt) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Prompt) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Prompt) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Prompt) GetInstruction() string {
+	if x != nil {
+		return x.Instruction
+	}
+	return ""
+}
+
+func (x *Prompt) GetQuestions() []string {
+	if x != nil {
+		return x.Questions
+	}
+	return nil
+}
+
+func (x *Prompt) GetEchos() []bool {
+	if x != nil {
+		return x.Echos
+	}
+	return nil
+}
+
+type PromptAnswer struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Answers []string               `protobuf:"bytes,2,rep,name=answers,proto3" json:"answers,omitempty"`
+	// cancel gives up authenticating with the prompt
+	Cancel        bool `protobuf:"varint,3,opt,name=cancel,proto3" json:"cancel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromptAnswer) Reset() {
+	*x = PromptAnswer{}
+	mi := &file_ctrl_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromptAnswer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromptAnswer) ProtoMessage() {}
+
+func (x *PromptAnswer) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromptAnswer.ProtoReflect.Descriptor instead.
+func (*PromptAnswer) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PromptAnswer) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PromptAnswer) GetAnswers() []string {
+	if x != nil {
+		return x.Answers
+	}
+	return nil
+}
+
+func (x *PromptAnswer) GetCancel() bool {
+	if x != nil {
+		return x.Cancel
+	}
+	return false
+}
+
+// OpenInteractiveRequest is sent by the client of OpenFwdInteractive, the first message has to be the
+// forwards to open and the following ones answer the prompts of the daemon.
+type OpenInteractiveRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Msg:
+	//
+	//	*OpenInteractiveRequest_Open
+	//	*OpenInteractiveRequest_Answer
+	Msg           isOpenInteractiveRequest_Msg `protobuf_oneof:"msg"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpenInteractiveRequest) Reset() {
+	*x = OpenInteractiveRequest{}
+	mi := &file_ctrl_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenInteractiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenInteractiveRequest) ProtoMessage() {}
+
+func (x *OpenInteractiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenInteractiveRequest.ProtoReflect.Descriptor instead.
+func (*OpenInteractiveRequest) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *OpenInteractiveRequest) GetMsg() isOpenInteractiveRequest_Msg {
+	if x != nil {
+		return x.Msg
+	}
+	return nil
+}
+
+func (x *OpenInteractiveRequest) GetOpen() *OpenRequest {
+	if x != nil {
+		if x, ok := x.Msg.(*OpenInteractiveRequest_Open); ok {
+			return x.Open
+		}
+	}
+	return nil
+}
+
+func (x *OpenInteractiveRequest) GetAnswer() *PromptAnswer {
+	if x != nil {
+		if x, ok := x.Msg.(*OpenInteractiveRequest_Answer); ok {
+			return x.Answer
+		}
+	}
+	return nil
+}
+
+type isOpenInteractiveRequest_Msg interface {
+	isOpenInteractiveRequest_Msg()
+}
+
+type OpenInteractiveRequest_Open struct {
+	Open *OpenRequest `protobuf:"bytes,1,opt,name=open,proto3,oneof"`
+}
+
+type OpenInteractiveRequest_Answer struct {
+	Answer *PromptAnswer `protobuf:"bytes,2,opt,name=answer,proto3,oneof"`
+}
+
+func (*OpenInteractiveRequest_Open) isOpenInteractiveRequest_Msg() {}
+
+func (*OpenInteractiveRequest_Answer) isOpenInteractiveRequest_Msg() {}
+
+// OpenInteractiveResponse is a prompt while the forwards are opened and the result once they are.
+type OpenInteractiveResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Msg:
+	//
+	//	*OpenInteractiveResponse_Prompt
+	//	*OpenInteractiveResponse_Result
+	Msg           isOpenInteractiveResponse_Msg `protobuf_oneof:"msg"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpenInteractiveResponse) Reset() {
+	*x = OpenInteractiveResponse{}
+	mi := &file_ctrl_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpenInteractiveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpenInteractiveResponse) ProtoMessage() {}
+
+func (x *OpenInteractiveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpenInteractiveResponse.ProtoReflect.Descriptor instead.
+func (*OpenInteractiveResponse) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *OpenInteractiveResponse) GetMsg() isOpenInteractiveResponse_Msg {
+	if x != nil {
+		return x.Msg
+	}
+	return nil
+}
+
+func (x *OpenInteractiveResponse) GetPrompt() *Prompt {
+	if x != nil {
+		if x, ok := x.Msg.(*OpenInteractiveResponse_Prompt); ok {
+			return x.Prompt
+		}
+	}
+	return nil
+}
+
+func (x *OpenInteractiveResponse) GetResult() *OpenResponse {
+	if x != nil {
+		if x, ok := x.Msg.(*OpenInteractiveResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isOpenInteractiveResponse_Msg interface {
+	isOpenInteractiveResponse_Msg()
+}
+
+type OpenInteractiveResponse_Prompt struct {
+	Prompt *Prompt `protobuf:"bytes,1,opt,name=prompt,proto3,oneof"`
+}
+
+type OpenInteractiveResponse_Result struct {
+	Result *OpenResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*OpenInteractiveResponse_Prompt) isOpenInteractiveResponse_Msg() {}
+
+func (*OpenInteractiveResponse_Result) isOpenInteractiveResponse_Msg() {}
+
+type CloseRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Ids   []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	// selector closes all forwards with matching labels in addition to ids
+	Selector string `protobuf:"bytes,2,opt,name=selector,proto3" json:"selector,omitempty"`
+	// all_users allows closing the forwards of every user instead of those of the caller, only allowed for admins
+	AllUsers      bool `protobuf:"varint,3,opt,name=all_users,json=allUsers,proto3" json:"all_users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseRequest) Reset() {
+	*x = CloseRequest{}
+	mi := &file_ctrl_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseRequest) ProtoMessage() {}
+
+func (x *CloseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseRequest.ProtoReflect.Descriptor instead.
+func (*CloseRequest) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CloseRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *CloseRequest) GetSelector() string {
+	if x != nil {
+		return x.Selector
+	}
+	return ""
+}
+
+func (x *CloseRequest) GetAllUsers() bool {
+	if x != nil {
+		return x.AllUsers
+	}
+	return false
+}
+
+type CloseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClosedIds     []string               `protobuf:"bytes,1,rep,name=closed_ids,json=closedIds,proto3" json:"closed_ids,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseResponse) Reset() {
+	*x = CloseResponse{}
+	mi := &file_ctrl_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseResponse) ProtoMessage() {}
+
+func (x *CloseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseResponse.ProtoReflect.Descriptor instead.
+func (*CloseResponse) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *CloseResponse) GetClosedIds() []string {
+	if x != nil {
+		return x.ClosedIds
+	}
+	return nil
+}
+
+func (x *CloseResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type FwdStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	BytesIn       uint64                 `protobuf:"varint,2,opt,name=bytes_in,json=bytesIn,proto3" json:"bytes_in,omitempty"`
+	BytesOut      uint64                 `protobuf:"varint,3,opt,name=bytes_out,json=bytesOut,proto3" json:"bytes_out,omitempty"`
+	ActiveConns   int64                  `protobuf:"varint,4,opt,name=active_conns,json=activeConns,proto3" json:"active_conns,omitempty"`
+	TotalConns    uint64                 `protobuf:"varint,5,opt,name=total_conns,json=totalConns,proto3" json:"total_conns,omitempty"`
+	DialFailures  uint64                 `protobuf:"varint,6,opt,name=dial_failures,json=dialFailures,proto3" json:"dial_failures,omitempty"`
+	LastActivity  int64                  `protobuf:"varint,7,opt,name=last_activity,json=lastActivity,proto3" json:"last_activity,omitempty"`
+	Rejected      uint64                 `protobuf:"varint,8,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FwdStats) Reset() {
+	*x = FwdStats{}
+	mi := &file_ctrl_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FwdStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FwdStats) ProtoMessage() {}
+
+func (x *FwdStats) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FwdStats.ProtoReflect.Descriptor instead.
+func (*FwdStats) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *FwdStats) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FwdStats) GetBytesIn() uint64 {
+	if x != nil {
+		return x.BytesIn
+	}
+	return 0
+}
+
+func (x *FwdStats) GetBytesOut() uint64 {
+	if x != nil {
+		return x.BytesOut
+	}
+	return 0
+}
+
+func (x *FwdStats) GetActiveConns() int64 {
+	if x != nil {
+		return x.ActiveConns
+	}
+	return 0
+}
+
+func (x *FwdStats) GetTotalConns() uint64 {
+	if x != nil {
+		return x.TotalConns
+	}
+	return 0
+}
+
+func (x *FwdStats) GetDialFailures() uint64 {
+	if x != nil {
+		return x.DialFailures
+	}
+	return 0
+}
+
+func (x *FwdStats) GetLastActivity() int64 {
+	if x != nil {
+		return x.LastActivity
+	}
+	return 0
+}
+
+func (x *FwdStats) GetRejected() uint64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+type StatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_ctrl_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *StatsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type StatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stats         []*FwdStats            `protobuf:"bytes,1,rep,name=stats,proto3" json:"stats,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_ctrl_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse) ProtoMessage() {}
+
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *StatsResponse) GetStats() []*FwdStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *StatsResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type InspectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectRequest) Reset() {
+	*x = InspectRequest{}
+	mi := &file_ctrl_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectRequest) ProtoMessage() {}
+
+func (x *InspectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectRequest.ProtoReflect.Descriptor instead.
+func (*InspectRequest) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *InspectRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type FwdDetails struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Fwd   *Fwd                   `protobuf:"bytes,1,opt,name=fwd,proto3" json:"fwd,omitempty"`
+	Stats *FwdStats              `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`
+	// running is false for forwards that are persisted but not open
+	Running       bool `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FwdDetails) Reset() {
+	*x = FwdDetails{}
+	mi := &file_ctrl_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FwdDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FwdDetails) ProtoMessage() {}
+
+func (x *FwdDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FwdDetails.ProtoReflect.Descriptor instead.
+func (*FwdDetails) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *FwdDetails) GetFwd() *Fwd {
+	if x != nil {
+		return x.Fwd
+	}
+	return nil
+}
+
+func (x *FwdDetails) GetStats() *FwdStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *FwdDetails) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+type InspectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fwds          []*FwdDetails          `protobuf:"bytes,1,rep,name=fwds,proto3" json:"fwds,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InspectResponse) Reset() {
+	*x = InspectResponse{}
+	mi := &file_ctrl_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InspectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InspectResponse) ProtoMessage() {}
+
+func (x *InspectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InspectResponse.ProtoReflect.Descriptor instead.
+func (*InspectResponse) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *InspectResponse) GetFwds() []*FwdDetails {
+	if x != nil {
+		return x.Fwds
+	}
+	return nil
+}
+
+func (x *InspectResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
 	}
 	return nil
 }
 
-type Fwd struct {
+type ApplyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Parent        *Tunnel                `protobuf:"bytes,2,opt,name=parent,proto3" json:"parent,omitempty"`
-	Addrs         *AddrPair              `protobuf:"bytes,3,opt,name=addrs,proto3" json:"addrs,omitempty"`
+	Owner         string                 `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	Tunnels       []*Tunnel              `protobuf:"bytes,2,rep,name=tunnels,proto3" json:"tunnels,omitempty"`
+	DryRun        bool                   `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Fwd) Reset() {
-	*x = Fwd{}
-	mi := &file_ctrl_proto_msgTypes[2]
+func (x *ApplyRequest) Reset() {
+	*x = ApplyRequest{}
+	mi := &file_ctrl_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Fwd) String() string {
+func (x *ApplyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Fwd) ProtoMessage() {}
+func (*ApplyRequest) ProtoMessage() {}
 
-func (x *Fwd) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[2]
+func (x *ApplyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -199,58 +1901,57 @@ func (x *Fwd) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Fwd.ProtoReflect.Descriptor instead.
-func (*Fwd) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ApplyRequest.ProtoReflect.Descriptor instead.
+func (*ApplyRequest) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *Fwd) GetId() string {
+func (x *ApplyRequest) GetOwner() string {
 	if x != nil {
-		return x.Id
+		return x.Owner
 	}
 	return ""
 }
 
-func (x *Fwd) GetParent() *Tunnel {
+func (x *ApplyRequest) GetTunnels() []*Tunnel {
 	if x != nil {
-		return x.Parent
+		return x.Tunnels
 	}
 	return nil
 }
 
-func (x *Fwd) GetAddrs() *AddrPair {
+func (x *ApplyRequest) GetDryRun() bool {
 	if x != nil {
-		return x.Addrs
+		return x.DryRun
 	}
-	return nil
+	return false
 }
 
-type FwdState struct {
+type ApplyChange struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Action        ApplyAction            `protobuf:"varint,2,opt,name=action,proto3,enum=ctrl.ApplyAction" json:"action,omitempty"`
 	Host          string                 `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
-	Port          uint32                 `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
-	Addrs         *AddrPair              `protobuf:"bytes,5,opt,name=addrs,proto3" json:"addrs,omitempty"`
+	Addrs         *AddrPair              `protobuf:"bytes,4,opt,name=addrs,proto3" json:"addrs,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *FwdState) Reset() {
-	*x = FwdState{}
-	mi := &file_ctrl_proto_msgTypes[3]
+func (x *ApplyChange) Reset() {
+	*x = ApplyChange{}
+	mi := &file_ctrl_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *FwdState) String() string {
+func (x *ApplyChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FwdState) ProtoMessage() {}
+func (*ApplyChange) ProtoMessage() {}
 
-func (x *FwdState) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[3]
+func (x *ApplyChange) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -261,67 +1962,62 @@ func (x *FwdState) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FwdState.ProtoReflect.Descriptor instead.
-func (*FwdState) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ApplyChange.ProtoReflect.Descriptor instead.
+func (*ApplyChange) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *FwdState) GetId() string {
+func (x *ApplyChange) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-func (x *FwdState) GetUser() string {
+func (x *ApplyChange) GetAction() ApplyAction {
 	if x != nil {
-		return x.User
+		return x.Action
 	}
-	return ""
+	return ApplyAction_APPLY_UNCHANGED
 }
 
-func (x *FwdState) GetHost() string {
+func (x *ApplyChange) GetHost() string {
 	if x != nil {
 		return x.Host
 	}
 	return ""
 }
 
-func (x *FwdState) GetPort() uint32 {
-	if x != nil {
-		return x.Port
-	}
-	return 0
-}
-
-func (x *FwdState) GetAddrs() *AddrPair {
+func (x *ApplyChange) GetAddrs() *AddrPair {
 	if x != nil {
 		return x.Addrs
 	}
 	return nil
 }
 
-type PsRequest struct {
+type ApplyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Changes       []*ApplyChange         `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PsRequest) Reset() {
-	*x = PsRequest{}
-	mi := &file_ctrl_proto_msgTypes[4]
+func (x *ApplyResponse) Reset() {
+	*x = ApplyResponse{}
+	mi := &file_ctrl_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PsRequest) String() string {
+func (x *ApplyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PsRequest) ProtoMessage() {}
+func (*ApplyResponse) ProtoMessage() {}
 
-func (x *PsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[4]
+func (x *ApplyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -332,34 +2028,57 @@ func (x *PsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PsRequest.ProtoReflect.Descriptor instead.
-func (*PsRequest) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ApplyResponse.ProtoReflect.Descriptor instead.
+func (*ApplyResponse) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{24}
 }
 
-type PsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Fwds          []*Fwd                 `protobuf:"bytes,1,rep,name=fwds,proto3" json:"fwds,omitempty"`
-	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+func (x *ApplyResponse) GetChanges() []*ApplyChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ApplyResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  EventType              `protobuf:"varint,1,opt,name=type,proto3,enum=ctrl.EventType" json:"type,omitempty"`
+	// unix nanoseconds
+	At       int64  `protobuf:"varint,2,opt,name=at,proto3" json:"at,omitempty"`
+	TunnelId string `protobuf:"bytes,3,opt,name=tunnel_id,json=tunnelId,proto3" json:"tunnel_id,omitempty"`
+	// empty for events of the whole tunnel
+	FwdId string `protobuf:"bytes,4,opt,name=fwd_id,json=fwdId,proto3" json:"fwd_id,omitempty"`
+	Host  string `protobuf:"bytes,5,opt,name=host,proto3" json:"host,omitempty"`
+	// the address of the peer of accepted and closed connections
+	Peer          string `protobuf:"bytes,6,opt,name=peer,proto3" json:"peer,omitempty"`
+	Error         string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	OwnerUid      uint32 `protobuf:"varint,8,opt,name=owner_uid,json=ownerUid,proto3" json:"owner_uid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PsResponse) Reset() {
-	*x = PsResponse{}
-	mi := &file_ctrl_proto_msgTypes[5]
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_ctrl_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PsResponse) String() string {
+func (x *Event) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PsResponse) ProtoMessage() {}
+func (*Event) ProtoMessage() {}
 
-func (x *PsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[5]
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -370,100 +2089,92 @@ func (x *PsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PsResponse.ProtoReflect.Descriptor instead.
-func (*PsResponse) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *PsResponse) GetFwds() []*Fwd {
+func (x *Event) GetType() EventType {
 	if x != nil {
-		return x.Fwds
+		return x.Type
 	}
-	return nil
+	return EventType_EVENT_UNKNOWN
 }
 
-func (x *PsResponse) GetErrors() []string {
+func (x *Event) GetAt() int64 {
 	if x != nil {
-		return x.Errors
+		return x.At
 	}
-	return nil
-}
-
-type OpenRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Tunnels       []*Tunnel              `protobuf:"bytes,1,rep,name=tunnels,proto3" json:"tunnels,omitempty"`
-	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	return 0
 }
 
-func (x *OpenRequest) Reset() {
-	*x = OpenRequest{}
-	mi := &file_ctrl_proto_msgTypes[6]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *Event) GetTunnelId() string {
+	if x != nil {
+		return x.TunnelId
+	}
+	return ""
 }
 
-func (x *OpenRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *Event) GetFwdId() string {
+	if x != nil {
+		return x.FwdId
+	}
+	return ""
 }
 
-func (*OpenRequest) ProtoMessage() {}
-
-func (x *OpenRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[6]
+func (x *Event) GetHost() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Host
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use OpenRequest.ProtoReflect.Descriptor instead.
-func (*OpenRequest) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{6}
+func (x *Event) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
 }
 
-func (x *OpenRequest) GetTunnels() []*Tunnel {
+func (x *Event) GetError() string {
 	if x != nil {
-		return x.Tunnels
+		return x.Error
 	}
-	return nil
+	return ""
 }
 
-func (x *OpenRequest) GetErrors() []string {
+func (x *Event) GetOwnerUid() uint32 {
 	if x != nil {
-		return x.Errors
+		return x.OwnerUid
 	}
-	return nil
+	return 0
 }
 
-type OpenResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	OpenedIds     []string               `protobuf:"bytes,1,rep,name=opened_ids,json=openedIds,proto3" json:"opened_ids,omitempty"`
-	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+type WatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// only events of these types are sent, all types if empty
+	Types []EventType `protobuf:"varint,1,rep,packed,name=types,proto3,enum=ctrl.EventType" json:"types,omitempty"`
+	// only events of these forwards, or their tunnels, are sent, all forwards if empty
+	Ids           []string `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *OpenResponse) Reset() {
-	*x = OpenResponse{}
-	mi := &file_ctrl_proto_msgTypes[7]
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_ctrl_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *OpenResponse) String() string {
+func (x *WatchRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*OpenResponse) ProtoMessage() {}
+func (*WatchRequest) ProtoMessage() {}
 
-func (x *OpenResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[7]
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -474,47 +2185,52 @@ func (x *OpenResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use OpenResponse.ProtoReflect.Descriptor instead.
-func (*OpenResponse) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{26}
 }
 
-func (x *OpenResponse) GetOpenedIds() []string {
+func (x *WatchRequest) GetTypes() []EventType {
 	if x != nil {
-		return x.OpenedIds
+		return x.Types
 	}
 	return nil
 }
 
-func (x *OpenResponse) GetErrors() []string {
+func (x *WatchRequest) GetIds() []string {
 	if x != nil {
-		return x.Errors
+		return x.Ids
 	}
 	return nil
 }
 
-type CloseRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Ids           []string               `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+type LogsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the id, name or id prefix of the forward
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// keep streaming new entries after the buffered ones
+	Follow bool `protobuf:"varint,2,opt,name=follow,proto3" json:"follow,omitempty"`
+	// only entries logged at or after since are sent, unix nanoseconds
+	Since         int64 `protobuf:"varint,3,opt,name=since,proto3" json:"since,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CloseRequest) Reset() {
-	*x = CloseRequest{}
-	mi := &file_ctrl_proto_msgTypes[8]
+func (x *LogsRequest) Reset() {
+	*x = LogsRequest{}
+	mi := &file_ctrl_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CloseRequest) String() string {
+func (x *LogsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CloseRequest) ProtoMessage() {}
+func (*LogsRequest) ProtoMessage() {}
 
-func (x *CloseRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[8]
+func (x *LogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -525,41 +2241,61 @@ func (x *CloseRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CloseRequest.ProtoReflect.Descriptor instead.
-func (*CloseRequest) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use LogsRequest.ProtoReflect.Descriptor instead.
+func (*LogsRequest) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{27}
 }
 
-func (x *CloseRequest) GetIds() []string {
+func (x *LogsRequest) GetId() string {
 	if x != nil {
-		return x.Ids
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-type CloseResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ClosedIds     []string               `protobuf:"bytes,1,rep,name=closed_ids,json=closedIds,proto3" json:"closed_ids,omitempty"`
-	Errors        []string               `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+func (x *LogsRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+func (x *LogsRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+type LogEntry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// unix nanoseconds
+	At       int64  `protobuf:"varint,1,opt,name=at,proto3" json:"at,omitempty"`
+	Level    string `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`
+	Message  string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	TunnelId string `protobuf:"bytes,4,opt,name=tunnel_id,json=tunnelId,proto3" json:"tunnel_id,omitempty"`
+	// empty for entries of the whole tunnel
+	FwdId         string            `protobuf:"bytes,5,opt,name=fwd_id,json=fwdId,proto3" json:"fwd_id,omitempty"`
+	Fields        map[string]string `protobuf:"bytes,6,rep,name=fields,proto3" json:"fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CloseResponse) Reset() {
-	*x = CloseResponse{}
-	mi := &file_ctrl_proto_msgTypes[9]
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	mi := &file_ctrl_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CloseResponse) String() string {
+func (x *LogEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CloseResponse) ProtoMessage() {}
+func (*LogEntry) ProtoMessage() {}
 
-func (x *CloseResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[9]
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_ctrl_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -570,34 +2306,64 @@ func (x *CloseResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CloseResponse.ProtoReflect.Descriptor instead.
-func (*CloseResponse) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_ctrl_proto_rawDescGZIP(), []int{28}
 }
 
-func (x *CloseResponse) GetClosedIds() []string {
+func (x *LogEntry) GetAt() int64 {
 	if x != nil {
-		return x.ClosedIds
+		return x.At
 	}
-	return nil
+	return 0
 }
 
-func (x *CloseResponse) GetErrors() []string {
+func (x *LogEntry) GetLevel() string {
 	if x != nil {
-		return x.Errors
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogEntry) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogEntry) GetTunnelId() string {
+	if x != nil {
+		return x.TunnelId
+	}
+	return ""
+}
+
+func (x *LogEntry) GetFwdId() string {
+	if x != nil {
+		return x.FwdId
+	}
+	return ""
+}
+
+func (x *LogEntry) GetFields() map[string]string {
+	if x != nil {
+		return x.Fields
 	}
 	return nil
 }
 
 type CloseAllRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// all_users closes the forwards of every user instead of those of the caller, only allowed for admins
+	AllUsers      bool `protobuf:"varint,1,opt,name=all_users,json=allUsers,proto3" json:"all_users,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CloseAllRequest) Reset() {
 	*x = CloseAllRequest{}
-	mi := &file_ctrl_proto_msgTypes[10]
+	mi := &file_ctrl_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -609,7 +2375,7 @@ func (x *CloseAllRequest) String() string {
 func (*CloseAllRequest) ProtoMessage() {}
 
 func (x *CloseAllRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[10]
+	mi := &file_ctrl_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -622,7 +2388,14 @@ func (x *CloseAllRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CloseAllRequest.ProtoReflect.Descriptor instead.
 func (*CloseAllRequest) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{10}
+	return file_ctrl_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *CloseAllRequest) GetAllUsers() bool {
+	if x != nil {
+		return x.AllUsers
+	}
+	return false
 }
 
 type CloseAllResponse struct {
@@ -635,7 +2408,7 @@ type CloseAllResponse struct {
 
 func (x *CloseAllResponse) Reset() {
 	*x = CloseAllResponse{}
-	mi := &file_ctrl_proto_msgTypes[11]
+	mi := &file_ctrl_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -647,7 +2420,7 @@ func (x *CloseAllResponse) String() string {
 func (*CloseAllResponse) ProtoMessage() {}
 
 func (x *CloseAllResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_ctrl_proto_msgTypes[11]
+	mi := &file_ctrl_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -660,7 +2433,7 @@ func (x *CloseAllResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CloseAllResponse.ProtoReflect.Descriptor instead.
 func (*CloseAllResponse) Descriptor() ([]byte, []int) {
-	return file_ctrl_proto_rawDescGZIP(), []int{11}
+	return file_ctrl_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *CloseAllResponse) GetOk() bool {
@@ -682,12 +2455,32 @@ var File_ctrl_proto protoreflect.FileDescriptor
 const file_ctrl_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"ctrl.proto\x12\x04ctrl\"H\n" +
+	"ctrl.proto\x12\x04ctrl\"`\n" +
+	"\tSocksOpts\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12#\n" +
+	"\rresolve_local\x18\x03 \x01(\bR\fresolveLocal\"\xa9\x03\n" +
 	"\bAddrPair\x12\x1c\n" +
 	"\tlocalAddr\x18\x01 \x01(\tR\tlocalAddr\x12\x1e\n" +
 	"\n" +
 	"remoteAddr\x18\x02 \x01(\tR\n" +
-	"remoteAddr\"\x90\x02\n" +
+	"remoteAddr\x12!\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\r.ctrl.FwdKindR\x04kind\x12%\n" +
+	"\x05socks\x18\x04 \x01(\v2\x0f.ctrl.SocksOptsR\x05socks\x12\x1f\n" +
+	"\vsocket_mode\x18\x05 \x01(\rR\n" +
+	"socketMode\x12\x12\n" +
+	"\x04lazy\x18\x06 \x01(\bR\x04lazy\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\a \x01(\x03R\texpiresAt\x12\x14\n" +
+	"\x05allow\x18\b \x03(\tR\x05allow\x12\x12\n" +
+	"\x04deny\x18\t \x03(\tR\x04deny\x12\x14\n" +
+	"\x05owner\x18\n" +
+	" \x01(\tR\x05owner\x12\x12\n" +
+	"\x04name\x18\v \x01(\tR\x04name\x122\n" +
+	"\x06labels\x18\f \x03(\v2\x1a.ctrl.AddrPair.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb7\x04\n" +
 	"\x06Tunnel\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04user\x18\x02 \x01(\tR\x04user\x12\x12\n" +
@@ -695,21 +2488,56 @@ const file_ctrl_proto_rawDesc = "" +
 	"\x04port\x18\x04 \x01(\rR\x04port\x12@\n" +
 	"\faddress_pair\x18\x05 \x03(\v2\x1d.ctrl.Tunnel.AddressPairEntryR\vaddressPair\x12\x0e\n" +
 	"\x02pw\x18\x06 \x01(\tR\x02pw\x12\x18\n" +
-	"\aprivkey\x18\a \x01(\fR\aprivkey\x1aN\n" +
+	"\aprivkey\x18\a \x01(\fR\aprivkey\x12\x1c\n" +
+	"\tconnected\x18\b \x01(\bR\tconnected\x12-\n" +
+	"\x12reconnect_attempts\x18\t \x01(\rR\x11reconnectAttempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\n" +
+	" \x01(\tR\tlastError\x12\x1b\n" +
+	"\trtt_nanos\x18\v \x01(\x03R\brttNanos\x12\x12\n" +
+	"\x04idle\x18\f \x01(\bR\x04idle\x120\n" +
+	"\x06labels\x18\r \x03(\v2\x18.ctrl.Tunnel.LabelsEntryR\x06labels\x12\x1b\n" +
+	"\towner_uid\x18\x0e \x01(\rR\bownerUid\x1aN\n" +
 	"\x10AddressPairEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12$\n" +
-	"\x05value\x18\x02 \x01(\v2\x0e.ctrl.AddrPairR\x05value:\x028\x01\"a\n" +
+	"\x05value\x18\x02 \x01(\v2\x0e.ctrl.AddrPairR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"^\n" +
+	"\rFwdTransition\x12'\n" +
+	"\x06status\x18\x01 \x01(\x0e2\x0f.ctrl.FwdStatusR\x06status\x12\x0e\n" +
+	"\x02at\x18\x02 \x01(\x03R\x02at\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\xe0\x01\n" +
 	"\x03Fwd\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12$\n" +
 	"\x06parent\x18\x02 \x01(\v2\f.ctrl.TunnelR\x06parent\x12$\n" +
-	"\x05addrs\x18\x03 \x01(\v2\x0e.ctrl.AddrPairR\x05addrs\"|\n" +
+	"\x05addrs\x18\x03 \x01(\v2\x0e.ctrl.AddrPairR\x05addrs\x12'\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x0f.ctrl.FwdStatusR\x06status\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x125\n" +
+	"\vtransitions\x18\x06 \x03(\v2\x13.ctrl.FwdTransitionR\vtransitions\"\xc3\x03\n" +
 	"\bFwdState\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04user\x18\x02 \x01(\tR\x04user\x12\x12\n" +
 	"\x04host\x18\x03 \x01(\tR\x04host\x12\x12\n" +
 	"\x04port\x18\x04 \x01(\rR\x04port\x12$\n" +
-	"\x05addrs\x18\x05 \x01(\v2\x0e.ctrl.AddrPairR\x05addrs\"\v\n" +
-	"\tPsRequest\"C\n" +
+	"\x05addrs\x18\x05 \x01(\v2\x0e.ctrl.AddrPairR\x05addrs\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\x03R\texpiresAt\x12\x12\n" +
+	"\x04name\x18\a \x01(\tR\x04name\x122\n" +
+	"\x06labels\x18\b \x03(\v2\x1a.ctrl.FwdState.LabelsEntryR\x06labels\x12E\n" +
+	"\rtunnel_labels\x18\t \x03(\v2 .ctrl.FwdState.TunnelLabelsEntryR\ftunnelLabels\x12\x1b\n" +
+	"\towner_uid\x18\n" +
+	" \x01(\rR\bownerUid\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a?\n" +
+	"\x11TunnelLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"D\n" +
+	"\tPsRequest\x12\x1a\n" +
+	"\bselector\x18\x01 \x01(\tR\bselector\x12\x1b\n" +
+	"\tall_users\x18\x02 \x01(\bR\ballUsers\"C\n" +
 	"\n" +
 	"PsResponse\x12\x1d\n" +
 	"\x04fwds\x18\x01 \x03(\v2\t.ctrl.FwdR\x04fwds\x12\x16\n" +
@@ -720,22 +2548,146 @@ const file_ctrl_proto_rawDesc = "" +
 	"\fOpenResponse\x12\x1d\n" +
 	"\n" +
 	"opened_ids\x18\x01 \x03(\tR\topenedIds\x12\x16\n" +
-	"\x06errors\x18\x02 \x03(\tR\x06errors\" \n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\"\xaa\x01\n" +
+	"\x06Prompt\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12 \n" +
+	"\vinstruction\x18\x05 \x01(\tR\vinstruction\x12\x1c\n" +
+	"\tquestions\x18\x06 \x03(\tR\tquestions\x12\x14\n" +
+	"\x05echos\x18\a \x03(\bR\x05echos\"P\n" +
+	"\fPromptAnswer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x18\n" +
+	"\aanswers\x18\x02 \x03(\tR\aanswers\x12\x16\n" +
+	"\x06cancel\x18\x03 \x01(\bR\x06cancel\"v\n" +
+	"\x16OpenInteractiveRequest\x12'\n" +
+	"\x04open\x18\x01 \x01(\v2\x11.ctrl.OpenRequestH\x00R\x04open\x12,\n" +
+	"\x06answer\x18\x02 \x01(\v2\x12.ctrl.PromptAnswerH\x00R\x06answerB\x05\n" +
+	"\x03msg\"v\n" +
+	"\x17OpenInteractiveResponse\x12&\n" +
+	"\x06prompt\x18\x01 \x01(\v2\f.ctrl.PromptH\x00R\x06prompt\x12,\n" +
+	"\x06result\x18\x02 \x01(\v2\x12.ctrl.OpenResponseH\x00R\x06resultB\x05\n" +
+	"\x03msg\"Y\n" +
 	"\fCloseRequest\x12\x10\n" +
-	"\x03ids\x18\x01 \x03(\tR\x03ids\"F\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\x12\x1a\n" +
+	"\bselector\x18\x02 \x01(\tR\bselector\x12\x1b\n" +
+	"\tall_users\x18\x03 \x01(\bR\ballUsers\"F\n" +
 	"\rCloseResponse\x12\x1d\n" +
 	"\n" +
 	"closed_ids\x18\x01 \x03(\tR\tclosedIds\x12\x16\n" +
-	"\x06errors\x18\x02 \x03(\tR\x06errors\"\x11\n" +
-	"\x0fCloseAllRequest\"8\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\"\xfc\x01\n" +
+	"\bFwdStats\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bbytes_in\x18\x02 \x01(\x04R\abytesIn\x12\x1b\n" +
+	"\tbytes_out\x18\x03 \x01(\x04R\bbytesOut\x12!\n" +
+	"\factive_conns\x18\x04 \x01(\x03R\vactiveConns\x12\x1f\n" +
+	"\vtotal_conns\x18\x05 \x01(\x04R\n" +
+	"totalConns\x12#\n" +
+	"\rdial_failures\x18\x06 \x01(\x04R\fdialFailures\x12#\n" +
+	"\rlast_activity\x18\a \x01(\x03R\flastActivity\x12\x1a\n" +
+	"\brejected\x18\b \x01(\x04R\brejected\" \n" +
+	"\fStatsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"M\n" +
+	"\rStatsResponse\x12$\n" +
+	"\x05stats\x18\x01 \x03(\v2\x0e.ctrl.FwdStatsR\x05stats\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\"\"\n" +
+	"\x0eInspectRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\tR\x03ids\"i\n" +
+	"\n" +
+	"FwdDetails\x12\x1b\n" +
+	"\x03fwd\x18\x01 \x01(\v2\t.ctrl.FwdR\x03fwd\x12$\n" +
+	"\x05stats\x18\x02 \x01(\v2\x0e.ctrl.FwdStatsR\x05stats\x12\x18\n" +
+	"\arunning\x18\x03 \x01(\bR\arunning\"O\n" +
+	"\x0fInspectResponse\x12$\n" +
+	"\x04fwds\x18\x01 \x03(\v2\x10.ctrl.FwdDetailsR\x04fwds\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\"e\n" +
+	"\fApplyRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x12&\n" +
+	"\atunnels\x18\x02 \x03(\v2\f.ctrl.TunnelR\atunnels\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"\x82\x01\n" +
+	"\vApplyChange\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12)\n" +
+	"\x06action\x18\x02 \x01(\x0e2\x11.ctrl.ApplyActionR\x06action\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12$\n" +
+	"\x05addrs\x18\x04 \x01(\v2\x0e.ctrl.AddrPairR\x05addrs\"T\n" +
+	"\rApplyResponse\x12+\n" +
+	"\achanges\x18\x01 \x03(\v2\x11.ctrl.ApplyChangeR\achanges\x12\x16\n" +
+	"\x06errors\x18\x02 \x03(\tR\x06errors\"\xcb\x01\n" +
+	"\x05Event\x12#\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x0f.ctrl.EventTypeR\x04type\x12\x0e\n" +
+	"\x02at\x18\x02 \x01(\x03R\x02at\x12\x1b\n" +
+	"\ttunnel_id\x18\x03 \x01(\tR\btunnelId\x12\x15\n" +
+	"\x06fwd_id\x18\x04 \x01(\tR\x05fwdId\x12\x12\n" +
+	"\x04host\x18\x05 \x01(\tR\x04host\x12\x12\n" +
+	"\x04peer\x18\x06 \x01(\tR\x04peer\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x1b\n" +
+	"\towner_uid\x18\b \x01(\rR\bownerUid\"G\n" +
+	"\fWatchRequest\x12%\n" +
+	"\x05types\x18\x01 \x03(\x0e2\x0f.ctrl.EventTypeR\x05types\x12\x10\n" +
+	"\x03ids\x18\x02 \x03(\tR\x03ids\"K\n" +
+	"\vLogsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06follow\x18\x02 \x01(\bR\x06follow\x12\x14\n" +
+	"\x05since\x18\x03 \x01(\x03R\x05since\"\xed\x01\n" +
+	"\bLogEntry\x12\x0e\n" +
+	"\x02at\x18\x01 \x01(\x03R\x02at\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1b\n" +
+	"\ttunnel_id\x18\x04 \x01(\tR\btunnelId\x12\x15\n" +
+	"\x06fwd_id\x18\x05 \x01(\tR\x05fwdId\x122\n" +
+	"\x06fields\x18\x06 \x03(\v2\x1a.ctrl.LogEntry.FieldsEntryR\x06fields\x1a9\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\".\n" +
+	"\x0fCloseAllRequest\x12\x1b\n" +
+	"\tall_users\x18\x01 \x01(\bR\ballUsers\"8\n" +
 	"\x10CloseAllResponse\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error2\xde\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error*G\n" +
+	"\aFwdKind\x12\r\n" +
+	"\tFWD_LOCAL\x10\x00\x12\x0e\n" +
+	"\n" +
+	"FWD_REMOTE\x10\x01\x12\x0f\n" +
+	"\vFWD_DYNAMIC\x10\x02\x12\f\n" +
+	"\bFWD_HTTP\x10\x03*\x88\x01\n" +
+	"\tFwdStatus\x12\x0f\n" +
+	"\vFWD_PENDING\x10\x00\x12\x0f\n" +
+	"\vFWD_DIALING\x10\x01\x12\x11\n" +
+	"\rFWD_LISTENING\x10\x02\x12\x10\n" +
+	"\fFWD_DEGRADED\x10\x03\x12\x14\n" +
+	"\x10FWD_RECONNECTING\x10\x04\x12\x0e\n" +
+	"\n" +
+	"FWD_FAILED\x10\x05\x12\x0e\n" +
+	"\n" +
+	"FWD_PAUSED\x10\x06*V\n" +
+	"\vApplyAction\x12\x13\n" +
+	"\x0fAPPLY_UNCHANGED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"APPLY_OPEN\x10\x01\x12\x0f\n" +
+	"\vAPPLY_CLOSE\x10\x02\x12\x11\n" +
+	"\rAPPLY_REPLACE\x10\x03*\xe5\x01\n" +
+	"\tEventType\x12\x11\n" +
+	"\rEVENT_UNKNOWN\x10\x00\x12\x17\n" +
+	"\x13EVENT_TUNNEL_DIALED\x10\x01\x12\x15\n" +
+	"\x11EVENT_AUTH_FAILED\x10\x02\x12\x17\n" +
+	"\x13EVENT_FWD_LISTENING\x10\x03\x12\x17\n" +
+	"\x13EVENT_CONN_ACCEPTED\x10\x04\x12\x15\n" +
+	"\x11EVENT_CONN_CLOSED\x10\x05\x12\x15\n" +
+	"\x11EVENT_TUNNEL_LOST\x10\x06\x12\x1c\n" +
+	"\x18EVENT_TUNNEL_RECONNECTED\x10\a\x12\x17\n" +
+	"\x13EVENT_TUNNEL_CLOSED\x10\b2\xaa\x04\n" +
 	"\rTunnelService\x12'\n" +
 	"\x02Ps\x12\x0f.ctrl.PsRequest\x1a\x10.ctrl.PsResponse\x120\n" +
-	"\aOpenFwd\x12\x11.ctrl.OpenRequest\x1a\x12.ctrl.OpenResponse\x123\n" +
+	"\aOpenFwd\x12\x11.ctrl.OpenRequest\x1a\x12.ctrl.OpenResponse\x12U\n" +
+	"\x12OpenFwdInteractive\x12\x1c.ctrl.OpenInteractiveRequest\x1a\x1d.ctrl.OpenInteractiveResponse(\x010\x01\x123\n" +
 	"\bCloseFwd\x12\x12.ctrl.CloseRequest\x1a\x13.ctrl.CloseResponse\x12=\n" +
-	"\fCloseAllFwds\x12\x15.ctrl.CloseAllRequest\x1a\x16.ctrl.CloseAllResponseB\x10Z\x0e./proto;ctrlpbb\x06proto3"
+	"\fCloseAllFwds\x12\x15.ctrl.CloseAllRequest\x1a\x16.ctrl.CloseAllResponse\x120\n" +
+	"\x05Stats\x12\x12.ctrl.StatsRequest\x1a\x13.ctrl.StatsResponse\x120\n" +
+	"\x05Apply\x12\x12.ctrl.ApplyRequest\x1a\x13.ctrl.ApplyResponse\x126\n" +
+	"\aInspect\x12\x14.ctrl.InspectRequest\x1a\x15.ctrl.InspectResponse\x12*\n" +
+	"\x05Watch\x12\x12.ctrl.WatchRequest\x1a\v.ctrl.Event0\x01\x12+\n" +
+	"\x04Logs\x12\x11.ctrl.LogsRequest\x1a\x0e.ctrl.LogEntry0\x01B\x10Z\x0e./proto;ctrlpbb\x06proto3"
 
 var (
 	file_ctrl_proto_rawDescOnce sync.Once
@@ -749,43 +2701,108 @@ func file_ctrl_proto_rawDescGZIP() []byte {
 	return file_ctrl_proto_rawDescData
 }
 
-var file_ctrl_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_ctrl_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_ctrl_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
 var file_ctrl_proto_goTypes = []any{
-	(*AddrPair)(nil),         // 0: ctrl.AddrPair
-	(*Tunnel)(nil),           // 1: ctrl.Tunnel
-	(*Fwd)(nil),              // 2: ctrl.Fwd
-	(*FwdState)(nil),         // 3: ctrl.FwdState
-	(*PsRequest)(nil),        // 4: ctrl.PsRequest
-	(*PsResponse)(nil),       // 5: ctrl.PsResponse
-	(*OpenRequest)(nil),      // 6: ctrl.OpenRequest
-	(*OpenResponse)(nil),     // 7: ctrl.OpenResponse
-	(*CloseRequest)(nil),     // 8: ctrl.CloseRequest
-	(*CloseResponse)(nil),    // 9: ctrl.CloseResponse
-	(*CloseAllRequest)(nil),  // 10: ctrl.CloseAllRequest
-	(*CloseAllResponse)(nil), // 11: ctrl.CloseAllResponse
-	nil,                      // 12: ctrl.Tunnel.AddressPairEntry
+	(FwdKind)(0),                    // 0: ctrl.FwdKind
+	(FwdStatus)(0),                  // 1: ctrl.FwdStatus
+	(ApplyAction)(0),                // 2: ctrl.ApplyAction
+	(EventType)(0),                  // 3: ctrl.EventType
+	(*SocksOpts)(nil),               // 4: ctrl.SocksOpts
+	(*AddrPair)(nil),                // 5: ctrl.AddrPair
+	(*Tunnel)(nil),                  // 6: ctrl.Tunnel
+	(*FwdTransition)(nil),           // 7: ctrl.FwdTransition
+	(*Fwd)(nil),                     // 8: ctrl.Fwd
+	(*FwdState)(nil),                // 9: ctrl.FwdState
+	(*PsRequest)(nil),               // 10: ctrl.PsRequest
+	(*PsResponse)(nil),              // 11: ctrl.PsResponse
+	(*OpenRequest)(nil),             // 12: ctrl.OpenRequest
+	(*OpenResponse)(nil),            // 13: ctrl.OpenResponse
+	(*Prompt)(nil),                  // 14: ctrl.Prompt
+	(*PromptAnswer)(nil),            // 15: ctrl.PromptAnswer
+	(*OpenInteractiveRequest)(nil),  // 16: ctrl.OpenInteractiveRequest
+	(*OpenInteractiveResponse)(nil), // 17: ctrl.OpenInteractiveResponse
+	(*CloseRequest)(nil),            // 18: ctrl.CloseRequest
+	(*CloseResponse)(nil),           // 19: ctrl.CloseResponse
+	(*FwdStats)(nil),                // 20: ctrl.FwdStats
+	(*StatsRequest)(nil),            // 21: ctrl.StatsRequest
+	(*StatsResponse)(nil),           // 22: ctrl.StatsResponse
+	(*InspectRequest)(nil),          // 23: ctrl.InspectRequest
+	(*FwdDetails)(nil),              // 24: ctrl.FwdDetails
+	(*InspectResponse)(nil),         // 25: ctrl.InspectResponse
+	(*ApplyRequest)(nil),            // 26: ctrl.ApplyRequest
+	(*ApplyChange)(nil),             // 27: ctrl.ApplyChange
+	(*ApplyResponse)(nil),           // 28: ctrl.ApplyResponse
+	(*Event)(nil),                   // 29: ctrl.Event
+	(*WatchRequest)(nil),            // 30: ctrl.WatchRequest
+	(*LogsRequest)(nil),             // 31: ctrl.LogsRequest
+	(*LogEntry)(nil),                // 32: ctrl.LogEntry
+	(*CloseAllRequest)(nil),         // 33: ctrl.CloseAllRequest
+	(*CloseAllResponse)(nil),        // 34: ctrl.CloseAllResponse
+	nil,                             // 35: ctrl.AddrPair.LabelsEntry
+	nil,                             // 36: ctrl.Tunnel.AddressPairEntry
+	nil,                             // 37: ctrl.Tunnel.LabelsEntry
+	nil,                             // 38: ctrl.FwdState.LabelsEntry
+	nil,                             // 39: ctrl.FwdState.TunnelLabelsEntry
+	nil,                             // 40: ctrl.LogEntry.FieldsEntry
 }
 var file_ctrl_proto_depIdxs = []int32{
-	12, // 0: ctrl.Tunnel.address_pair:type_name -> ctrl.Tunnel.AddressPairEntry
-	1,  // 1: ctrl.Fwd.parent:type_name -> ctrl.Tunnel
-	0,  // 2: ctrl.Fwd.addrs:type_name -> ctrl.AddrPair
-	0,  // 3: ctrl.FwdState.addrs:type_name -> ctrl.AddrPair
-	2,  // 4: ctrl.PsResponse.fwds:type_name -> ctrl.Fwd
-	1,  // 5: ctrl.OpenRequest.tunnels:type_name -> ctrl.Tunnel
-	0,  // 6: ctrl.Tunnel.AddressPairEntry.value:type_name -> ctrl.AddrPair
-	4,  // 7: ctrl.TunnelService.Ps:input_type -> ctrl.PsRequest
-	6,  // 8: ctrl.TunnelService.OpenFwd:input_type -> ctrl.OpenRequest
-	8,  // 9: ctrl.TunnelService.CloseFwd:input_type -> ctrl.CloseRequest
-	10, // 10: ctrl.TunnelService.CloseAllFwds:input_type -> ctrl.CloseAllRequest
-	5,  // 11: ctrl.TunnelService.Ps:output_type -> ctrl.PsResponse
-	7,  // 12: ctrl.TunnelService.OpenFwd:output_type -> ctrl.OpenResponse
-	9,  // 13: ctrl.TunnelService.CloseFwd:output_type -> ctrl.CloseResponse
-	11, // 14: ctrl.TunnelService.CloseAllFwds:output_type -> ctrl.CloseAllResponse
-	11, // [11:15] is the sub-list for method output_type
-	7,  // [7:11] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	0,  // 0: ctrl.AddrPair.kind:type_name -> ctrl.FwdKind
+	4,  // 1: ctrl.AddrPair.socks:type_name -> ctrl.SocksOpts
+	35, // 2: ctrl.AddrPair.labels:type_name -> ctrl.AddrPair.LabelsEntry
+	36, // 3: ctrl.Tunnel.address_pair:type_name -> ctrl.Tunnel.AddressPairEntry
+	37, // 4: ctrl.Tunnel.labels:type_name -> ctrl.Tunnel.LabelsEntry
+	1,  // 5: ctrl.FwdTransition.status:type_name -> ctrl.FwdStatus
+	6,  // 6: ctrl.Fwd.parent:type_name -> ctrl.Tunnel
+	5,  // 7: ctrl.Fwd.addrs:type_name -> ctrl.AddrPair
+	1,  // 8: ctrl.Fwd.status:type_name -> ctrl.FwdStatus
+	7,  // 9: ctrl.Fwd.transitions:type_name -> ctrl.FwdTransition
+	5,  // 10: ctrl.FwdState.addrs:type_name -> ctrl.AddrPair
+	38, // 11: ctrl.FwdState.labels:type_name -> ctrl.FwdState.LabelsEntry
+	39, // 12: ctrl.FwdState.tunnel_labels:type_name -> ctrl.FwdState.TunnelLabelsEntry
+	8,  // 13: ctrl.PsResponse.fwds:type_name -> ctrl.Fwd
+	6,  // 14: ctrl.OpenRequest.tunnels:type_name -> ctrl.Tunnel
+	12, // 15: ctrl.OpenInteractiveRequest.open:type_name -> ctrl.OpenRequest
+	15, // 16: ctrl.OpenInteractiveRequest.answer:type_name -> ctrl.PromptAnswer
+	14, // 17: ctrl.OpenInteractiveResponse.prompt:type_name -> ctrl.Prompt
+	13, // 18: ctrl.OpenInteractiveResponse.result:type_name -> ctrl.OpenResponse
+	20, // 19: ctrl.StatsResponse.stats:type_name -> ctrl.FwdStats
+	8,  // 20: ctrl.FwdDetails.fwd:type_name -> ctrl.Fwd
+	20, // 21: ctrl.FwdDetails.stats:type_name -> ctrl.FwdStats
+	24, // 22: ctrl.InspectResponse.fwds:type_name -> ctrl.FwdDetails
+	6,  // 23: ctrl.ApplyRequest.tunnels:type_name -> ctrl.Tunnel
+	2,  // 24: ctrl.ApplyChange.action:type_name -> ctrl.ApplyAction
+	5,  // 25: ctrl.ApplyChange.addrs:type_name -> ctrl.AddrPair
+	27, // 26: ctrl.ApplyResponse.changes:type_name -> ctrl.ApplyChange
+	3,  // 27: ctrl.Event.type:type_name -> ctrl.EventType
+	3,  // 28: ctrl.WatchRequest.types:type_name -> ctrl.EventType
+	40, // 29: ctrl.LogEntry.fields:type_name -> ctrl.LogEntry.FieldsEntry
+	5,  // 30: ctrl.Tunnel.AddressPairEntry.value:type_name -> ctrl.AddrPair
+	10, // 31: ctrl.TunnelService.Ps:input_type -> ctrl.PsRequest
+	12, // 32: ctrl.TunnelService.OpenFwd:input_type -> ctrl.OpenRequest
+	16, // 33: ctrl.TunnelService.OpenFwdInteractive:input_type -> ctrl.OpenInteractiveRequest
+	18, // 34: ctrl.TunnelService.CloseFwd:input_type -> ctrl.CloseRequest
+	33, // 35: ctrl.TunnelService.CloseAllFwds:input_type -> ctrl.CloseAllRequest
+	21, // 36: ctrl.TunnelService.Stats:input_type -> ctrl.StatsRequest
+	26, // 37: ctrl.TunnelService.Apply:input_type -> ctrl.ApplyRequest
+	23, // 38: ctrl.TunnelService.Inspect:input_type -> ctrl.InspectRequest
+	30, // 39: ctrl.TunnelService.Watch:input_type -> ctrl.WatchRequest
+	31, // 40: ctrl.TunnelService.Logs:input_type -> ctrl.LogsRequest
+	11, // 41: ctrl.TunnelService.Ps:output_type -> ctrl.PsResponse
+	13, // 42: ctrl.TunnelService.OpenFwd:output_type -> ctrl.OpenResponse
+	17, // 43: ctrl.TunnelService.OpenFwdInteractive:output_type -> ctrl.OpenInteractiveResponse
+	19, // 44: ctrl.TunnelService.CloseFwd:output_type -> ctrl.CloseResponse
+	34, // 45: ctrl.TunnelService.CloseAllFwds:output_type -> ctrl.CloseAllResponse
+	22, // 46: ctrl.TunnelService.Stats:output_type -> ctrl.StatsResponse
+	28, // 47: ctrl.TunnelService.Apply:output_type -> ctrl.ApplyResponse
+	25, // 48: ctrl.TunnelService.Inspect:output_type -> ctrl.InspectResponse
+	29, // 49: ctrl.TunnelService.Watch:output_type -> ctrl.Event
+	32, // 50: ctrl.TunnelService.Logs:output_type -> ctrl.LogEntry
+	41, // [41:51] is the sub-list for method output_type
+	31, // [31:41] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
 }
 
 func init() { file_ctrl_proto_init() }
@@ -793,18 +2810,27 @@ func file_ctrl_proto_init() {
 	if File_ctrl_proto != nil {
 		return
 	}
+	file_ctrl_proto_msgTypes[12].OneofWrappers = []any{
+		(*OpenInteractiveRequest_Open)(nil),
+		(*OpenInteractiveRequest_Answer)(nil),
+	}
+	file_ctrl_proto_msgTypes[13].OneofWrappers = []any{
+		(*OpenInteractiveResponse_Prompt)(nil),
+		(*OpenInteractiveResponse_Result)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ctrl_proto_rawDesc), len(file_ctrl_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   13,
+			NumEnums:      4,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_ctrl_proto_goTypes,
 		DependencyIndexes: file_ctrl_proto_depIdxs,
+		EnumInfos:         file_ctrl_proto_enumTypes,
 		MessageInfos:      file_ctrl_proto_msgTypes,
 	}.Build()
 	File_ctrl_proto = out.File
@@ -19,10 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TunnelService_Ps_FullMethodName           = "/ctrl.TunnelService/Ps"
-	TunnelService_OpenFwd_FullMethodName      = "/ctrl.TunnelService/OpenFwd"
-	TunnelService_CloseFwd_FullMethodName     = "/ctrl.TunnelService/CloseFwd"
-	TunnelService_CloseAllFwds_FullMethodName = "/ctrl.TunnelService/CloseAllFwds"
+	TunnelService_Ps_FullMethodName                 = "/ctrl.TunnelService/Ps"
+	TunnelService_OpenFwd_FullMethodName            = "/ctrl.TunnelService/OpenFwd"
+	TunnelService_OpenFwdInteractive_FullMethodName = "/ctrl.TunnelService/OpenFwdInteractive"
+	TunnelService_CloseFwd_FullMethodName           = "/ctrl.TunnelService/CloseFwd"
+	TunnelService_CloseAllFwds_FullMethodName       = "/ctrl.TunnelService/CloseAllFwds"
+	TunnelService_Stats_FullMethodName              = "/ctrl.TunnelService/Stats"
+	TunnelService_Apply_FullMethodName              = "/ctrl.TunnelService/Apply"
+	TunnelService_Inspect_FullMethodName            = "/ctrl.TunnelService/Inspect"
+	TunnelService_Watch_FullMethodName              = "/ctrl.TunnelService/Watch"
+	TunnelService_Logs_FullMethodName               = "/ctrl.TunnelService/Logs"
 )
 
 // TunnelServiceClient is the client API for TunnelService service.
@@ -31,8 +37,15 @@ const (
 type TunnelServiceClient interface {
 	Ps(ctx context.Context, in *PsRequest, opts ...grpc.CallOption) (*PsResponse, error)
 	OpenFwd(ctx context.Context, in *OpenRequest, opts ...grpc.CallOption) (*OpenResponse, error)
+	// OpenFwdInteractive opens forwards like OpenFwd while relaying the authentication prompts of ssh servers to the client
+	OpenFwdInteractive(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[OpenInteractiveRequest, OpenInteractiveResponse], error)
 	CloseFwd(ctx context.Context, in *CloseRequest, opts ...grpc.CallOption) (*CloseResponse, error)
 	CloseAllFwds(ctx context.Context, in *CloseAllRequest, opts ...grpc.CallOption) (*CloseAllResponse, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyResponse, error)
+	Inspect(ctx context.Context, in *InspectRequest, opts ...grpc.CallOption) (*InspectResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error)
 }
 
 type tunnelServiceClient struct {
@@ -63,6 +76,19 @@ func (c *tunnelServiceClient) OpenFwd(ctx context.Context, in *OpenRequest, opts
 	return out, nil
 }
 
+func (c *tunnelServiceClient) OpenFwdInteractive(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[OpenInteractiveRequest, OpenInteractiveResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TunnelService_ServiceDesc.Streams[0], TunnelService_OpenFwdInteractive_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[OpenInteractiveRequest, OpenInteractiveResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TunnelService_OpenFwdInteractiveClient = grpc.BidiStreamingClient[OpenInteractiveRequest, OpenInteractiveResponse]
+
 func (c *tunnelServiceClient) CloseFwd(ctx context.Context, in *CloseRequest, opts ...grpc.CallOption) (*CloseResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CloseResponse)
@@ -83,14 +109,89 @@ func (c *tunnelServiceClient) CloseAllFwds(ctx context.Context, in *CloseAllRequ
 	return out, nil
 }
 
+func (c *tunnelServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, TunnelService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tunnelServiceClient) Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyResponse)
+	err := c.cc.Invoke(ctx, TunnelService_Apply_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tunnelServiceClient) Inspect(ctx context.Context, in *InspectRequest, opts ...grpc.CallOption) (*InspectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InspectResponse)
+	err := c.cc.Invoke(ctx, TunnelService_Inspect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tunnelServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TunnelService_ServiceDesc.Streams[1], TunnelService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TunnelService_WatchClient = grpc.ServerStreamingClient[Event]
+
+func (c *tunnelServiceClient) Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TunnelService_ServiceDesc.Streams[2], TunnelService_Logs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LogsRequest, LogEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TunnelService_LogsClient = grpc.ServerStreamingClient[LogEntry]
+
 // TunnelServiceServer is the server API for TunnelService service.
 // All implementations must embed UnimplementedTunnelServiceServer
 // for forward compatibility.
 type TunnelServiceServer interface {
 	Ps(context.Context, *PsRequest) (*PsResponse, error)
 	OpenFwd(context.Context, *OpenRequest) (*OpenResponse, error)
+	// OpenFwdInteractive opens forwards like OpenFwd while relaying the authentication prompts of ssh servers to the client
+	OpenFwdInteractive(grpc.BidiStreamingServer[OpenInteractiveRequest, OpenInteractiveResponse]) error
 	CloseFwd(context.Context, *CloseRequest) (*CloseResponse, error)
 	CloseAllFwds(context.Context, *CloseAllRequest) (*CloseAllResponse, error)
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	Apply(context.Context, *ApplyRequest) (*ApplyResponse, error)
+	Inspect(context.Context, *InspectRequest) (*InspectResponse, error)
+	Watch(*WatchRequest, grpc.ServerStreamingServer[Event]) error
+	Logs(*LogsRequest, grpc.ServerStreamingServer[LogEntry]) error
 	mustEmbedUnimplementedTunnelServiceServer()
 }
 
@@ -107,12 +208,30 @@ func (UnimplementedTunnelServiceServer) Ps(context.Context, *PsRequest) (*PsResp
 func (UnimplementedTunnelServiceServer) OpenFwd(context.Context, *OpenRequest) (*OpenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method OpenFwd not implemented")
 }
+func (UnimplementedTunnelServiceServer) OpenFwdInteractive(grpc.BidiStreamingServer[OpenInteractiveRequest, OpenInteractiveResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method OpenFwdInteractive not implemented")
+}
 func (UnimplementedTunnelServiceServer) CloseFwd(context.Context, *CloseRequest) (*CloseResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CloseFwd not implemented")
 }
 func (UnimplementedTunnelServiceServer) CloseAllFwds(context.Context, *CloseAllRequest) (*CloseAllResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CloseAllFwds not implemented")
 }
+func (UnimplementedTunnelServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedTunnelServiceServer) Apply(context.Context, *ApplyRequest) (*ApplyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Apply not implemented")
+}
+func (UnimplementedTunnelServiceServer) Inspect(context.Context, *InspectRequest) (*InspectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Inspect not implemented")
+}
+func (UnimplementedTunnelServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedTunnelServiceServer) Logs(*LogsRequest, grpc.ServerStreamingServer[LogEntry]) error {
+	return status.Errorf(codes.Unimplemented, "method Logs not implemented")
+}
 func (UnimplementedTunnelServiceServer) mustEmbedUnimplementedTunnelServiceServer() {}
 func (UnimplementedTunnelServiceServer) testEmbeddedByValue()                       {}
 
@@ -170,6 +289,13 @@ func _TunnelService_OpenFwd_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TunnelService_OpenFwdInteractive_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TunnelServiceServer).OpenFwdInteractive(&grpc.GenericServerStream[OpenInteractiveRequest, OpenInteractiveResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TunnelService_OpenFwdInteractiveServer = grpc.BidiStreamingServer[OpenInteractiveRequest, OpenInteractiveResponse]
+
 func _TunnelService_CloseFwd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CloseRequest)
 	if err := dec(in); err != nil {
@@ -206,6 +332,82 @@ func _TunnelService_CloseAllFwds_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TunnelService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TunnelServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TunnelService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TunnelServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TunnelService_Apply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TunnelServiceServer).Apply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TunnelService_Apply_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TunnelServiceServer).Apply(ctx, req.(*ApplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TunnelService_Inspect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TunnelServiceServer).Inspect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TunnelService_Inspect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TunnelServiceServer).Inspect(ctx, req.(*InspectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TunnelService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TunnelServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TunnelService_WatchServer = grpc.ServerStreamingServer[Event]
+
+func _TunnelService_Logs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TunnelServiceServer).Logs(m, &grpc.GenericServerStream[LogsRequest, LogEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TunnelService_LogsServer = grpc.ServerStreamingServer[LogEntry]
+
 // TunnelService_ServiceDesc is the grpc.ServiceDesc for TunnelService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,7 +431,36 @@ var TunnelService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CloseAllFwds",
 			Handler:    _TunnelService_CloseAllFwds_Handler,
 		},
+		{
+			MethodName: "Stats",
+			Handler:    _TunnelService_Stats_Handler,
+		},
+		{
+			MethodName: "Apply",
+			Handler:    _TunnelService_Apply_Handler,
+		},
+		{
+			MethodName: "Inspect",
+			Handler:    _TunnelService_Inspect_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "OpenFwdInteractive",
+			Handler:       _TunnelService_OpenFwdInteractive_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _TunnelService_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Logs",
+			Handler:       _TunnelService_Logs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "ctrl.proto",
 }
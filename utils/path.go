@@ -14,3 +14,8 @@ func EvalPath(path string) string {
 	}
 	return path
 }
+
+// IsSocketPath reports if addr is a unix socket path rather than a host:port address.
+func IsSocketPath(addr string) bool {
+	return strings.HasPrefix(addr, "/")
+}